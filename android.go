@@ -0,0 +1,82 @@
+package uaparser
+
+import (
+	"strings"
+)
+
+// Android build ids start with the release codename letter, the next letters
+// are the branch, e.g. MRA58K is a Marshmallow (6.0) release branch build and
+// PQ3A.190801.002 is a Pie (9) build.
+var androidBuilds = map[string]string{
+	"e": "2.1", // eclair
+	"f": "2.2", // froyo
+	"g": "2.3", // gingerbread
+	"h": "3.0", // honeycomb
+	"i": "4.0", // ice cream sandwich
+	"j": "4.1", // jelly bean
+	"k": "4.4", // kitkat
+	"l": "5.0", // lollipop
+	"m": "6.0", // marshmallow
+	"n": "7.0", // nougat
+	"o": "8.0", // oreo
+	"p": "9",   // pie
+	"q": "10",
+	"r": "11",
+	"s": "12",
+	"t": "13",
+	"u": "14",
+
+	"ap": "15",
+	"bp": "16",
+
+	"hmj": "3.1", "htj": "3.2",
+	"jzo": "4.1.2", "jop": "4.2", "jdq": "4.2.2", "jwr": "4.3", "jss": "4.3", "jls": "4.3",
+	"ktu": "4.4.4",
+	"lmy": "5.1",
+	"mmb": "6.0.1", "mob": "6.0.1", "mhc": "6.0.1", "mtc": "6.0.1", "mxc": "6.0.1",
+	"nmf": "7.1", "n2g": "7.1.2", "njh": "7.1.2", "nhg": "7.1.2", "n4f": "7.1.1", "nof": "7.1.1",
+	"opm": "8.1",
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' }
+
+// androidBuildVersion maps an AOSP style build id (lower case) to the
+// android release it was cut from. Vendor build ids are not recognized.
+func androidBuildVersion(build string) (string, bool) {
+	id := build
+	if i := strings.IndexByte(build, '.'); i >= 0 {
+		// PPR1.180610.011
+		id = build[:i]
+		if len(id) != 4 || !isLetter(id[0]) || !isLetter(id[1]) || len(build) < i+7 {
+			return "", false
+		}
+		for j := i + 1; j < i+7; j++ {
+			if !isDigit(build[j]) {
+				return "", false
+			}
+		}
+	} else {
+		// MRA58K, LMY47V, N2G47O
+		if len(id) < 5 || !isLetter(id[0]) || !isDigit(id[3]) || !isDigit(id[4]) {
+			return "", false
+		}
+	}
+
+	for n := 3; n > 0; n-- {
+		if v, ok := androidBuilds[id[:n]]; ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// isWearOSModel reports whether an android device id is a Wear OS watch
+func isWearOSModel(name string) bool {
+	for _, x := range wearOSModels {
+		if strings.HasPrefix(name, x) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package uaparser
+
+import (
+	"strings"
+)
+
+// architecture tokens and what they stand for
+var archs = map[string]string{
+	"x86_64":  "x86_64",
+	"amd64":   "x86_64",
+	"x64":     "x86_64",
+	"win64":   "x86_64",
+	"wow64":   "x86", // 32 bit browser on 64 bit windows
+	"i386":    "x86",
+	"i486":    "x86",
+	"i586":    "x86",
+	"i686":    "x86",
+	"x86":     "x86",
+	"arm":     "arm",
+	"armv6l":  "arm",
+	"armv7":   "arm",
+	"armv7l":  "arm",
+	"armv8l":  "arm", // 32 bit userland on arm64
+	"aarch64": "arm64",
+	"arm64":   "arm64",
+	"armv8":   "arm64",
+	"mips":    "mips",
+	"mipsel":  "mips",
+	"mips64":  "mips",
+	"ppc":     "ppc",
+	"powerpc": "ppc",
+	"ppc64":   "ppc",
+	"ppc64le": "ppc",
+}
+
+var archBits = map[string]int{
+	"x86_64":  64,
+	"amd64":   64,
+	"x64":     64,
+	"win64":   64,
+	"aarch64": 64,
+	"arm64":   64,
+	"armv8":   64,
+	"mips64":  64,
+	"ppc64":   64,
+	"ppc64le": 64,
+}
+
+// the os architecture of tokens naming a 32 bit process on a 64 bit os
+var osArchs = map[string]string{
+	"wow64": "x86_64",
+}
+
+// setArch sets the process architecture and the os one, which only differs
+// for 32 bit processes on a 64 bit os
+func (ua *UserAgent) setArch(token string) {
+	ua.Arch = archs[token]
+	ua.OSArch = ua.Arch
+	if a, ok := osArchs[token]; ok {
+		ua.OSArch = a
+	}
+	ua.Bits = 32
+	if b, ok := archBits[token]; ok {
+		ua.Bits = b
+	}
+}
+
+// splitArch takes architecture words out of an os version,
+// "i686 on x86_64" is an x86 process on an x86_64 os with no version left
+func splitArch(version string) (rest, arch, osArch string) {
+	var words []string
+	on := false
+	for _, w := range strings.Fields(version) {
+		if _, ok := archs[w]; ok {
+			if on {
+				osArch = w
+			} else {
+				arch = w
+			}
+		} else if w == "on" {
+			on = true
+		} else {
+			words = append(words, w)
+		}
+	}
+	if arch == "" && osArch == "" {
+		return version, "", ""
+	}
+	return strings.Join(words, " "), arch, osArch
+}
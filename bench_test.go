@@ -0,0 +1,83 @@
+package uaparser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jdeng/uaparser/internal/corpus"
+)
+
+var benchCategories = []string{"desktop", "mobile", "app", "tv", "bot"}
+
+func BenchmarkParse(b *testing.B) {
+	_, byCategory, err := corpus.Read("testdata/bench.tsv")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var all []string
+	for _, c := range benchCategories {
+		uas := byCategory[c]
+		all = append(all, uas...)
+		b.Run(c, func(b *testing.B) {
+			if len(uas) == 0 {
+				b.Skipf("no %s user agents in testdata/bench.tsv", c)
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Parse(uas[i%len(uas)])
+			}
+		})
+	}
+	b.Run("all", func(b *testing.B) {
+		if len(all) == 0 {
+			b.Skip("no user agents in testdata/bench.tsv")
+		}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			Parse(all[i%len(all)])
+		}
+	})
+}
+
+const benchUA = "Mozilla/5.0 (Linux; Android 11; Pixel 5 Build/RQ3A.210805.001.A1; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/92.0.4515.159 Mobile Safari/537.36"
+
+func Benchmark_parse(b *testing.B) {
+	s := strings.ToLower(benchUA)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parse(s)
+	}
+}
+
+func BenchmarkParseComment(b *testing.B) {
+	s := "linux; android 11; pixel 5 build/rq3a.210805.001.a1; wv"
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		parseComment(s)
+	}
+}
+
+func BenchmarkTry(b *testing.B) {
+	ua := &UserAgent{tags: make(map[string]string)}
+	secs := []*section{{name: "chrome", version: "92.0.4515.159"}, {name: "applewebkit", version: "537.36"}, {name: "unknown", version: "1.0"}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sec := *secs[i%len(secs)]
+		ua.try(&sec, true)
+	}
+}
+
+// BenchmarkPrefixScan measures the lookup in commentPrefixRecognizers for
+// a section with a long prefix and one that matches nothing
+func BenchmarkPrefixScan(b *testing.B) {
+	for _, name := range []string{"xros 1.0", "pixel 5 build/rq3a.210805.001.a1"} {
+		b.Run(name, func(b *testing.B) {
+			ua := &UserAgent{tags: make(map[string]string)}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				sec := section{name: name}
+				tryPrefix(ua, commentPrefixRecognizers, &sec)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// uabench prints ns/op, allocs/op and throughput of Parse per UA category
+// of a corpus, category<TAB>user agent per line:
+//
+//	go run ./cmd/uabench -f testdata/bench.tsv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/jdeng/uaparser"
+	"github.com/jdeng/uaparser/internal/corpus"
+)
+
+func bench(uas []string) testing.BenchmarkResult {
+	return testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			uaparser.Parse(uas[i%len(uas)])
+		}
+	})
+}
+
+func main() {
+	file := flag.String("f", "testdata/bench.tsv", "corpus, category<TAB>user agent per line")
+	flag.Parse()
+
+	categories, byCategory, err := corpus.Read(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var all []string
+	fmt.Printf("%-10s %6s %10s %10s %10s %12s\n", "category", "uas", "ns/op", "allocs/op", "B/op", "uas/s")
+	report := func(c string, uas []string) {
+		if len(uas) == 0 {
+			fmt.Printf("%-10s %6d\n", c, 0)
+			return
+		}
+		r := bench(uas)
+		fmt.Printf("%-10s %6d %10d %10d %10d %12.0f\n", c, len(uas), r.NsPerOp(), r.AllocsPerOp(), r.AllocedBytesPerOp(), 1e9/float64(r.NsPerOp()))
+	}
+	for _, c := range categories {
+		all = append(all, byCategory[c]...)
+		report(c, byCategory[c])
+	}
+	report("all", all)
+}
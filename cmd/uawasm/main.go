@@ -0,0 +1,51 @@
+//go:build !js
+
+// uawasm reads one user agent per line from stdin and writes one JSON
+// result per line to stdout. A line starting with { is a JSON object of
+// HTTP headers and goes through ParseHeaders. Build it for WASI with
+//
+//	GOOS=wasip1 GOARCH=wasm go build -o uaparser.wasm ./cmd/uawasm
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/jdeng/uaparser"
+)
+
+func parseLine(line string) (*uaparser.UserAgent, error) {
+	if !strings.HasPrefix(line, "{") {
+		return uaparser.Parse(line), nil
+	}
+
+	var headers map[string]string
+	if err := json.Unmarshal([]byte(line), &headers); err != nil {
+		return nil, err
+	}
+	h := make(http.Header)
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	return uaparser.ParseHeaders(h), nil
+}
+
+func main() {
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	enc := json.NewEncoder(out)
+	for in.Scan() {
+		ua, err := parseLine(in.Text())
+		if err != nil {
+			enc.Encode(map[string]string{"error": err.Error()})
+			continue
+		}
+		enc.Encode(ua)
+	}
+}
@@ -0,0 +1,76 @@
+//go:build js && wasm
+
+// uawasm exposes the parser to JavaScript as a global uaparser object:
+//
+//	uaparser.parse(navigator.userAgent)
+//	uaparser.parseHeaders(request.headers) // a Headers instance or a plain object
+//
+// Results are the JSON of UserAgent as plain objects. Build with
+//
+//	GOOS=js GOARCH=wasm go build -o uaparser.wasm ./cmd/uawasm
+//
+// and load it with wasm_exec.js from $(go env GOROOT)/lib/wasm.
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"syscall/js"
+
+	"github.com/jdeng/uaparser"
+)
+
+func toJS(ua *uaparser.UserAgent) any {
+	b, err := json.Marshal(ua)
+	if err != nil {
+		return js.Null()
+	}
+	return js.Global().Get("JSON").Call("parse", string(b))
+}
+
+// headers converts a fetch Headers instance or a plain object
+func headers(v js.Value) http.Header {
+	h := make(http.Header)
+	if v.Type() != js.TypeObject {
+		return h
+	}
+
+	if get := v.Get("get"); get.Type() == js.TypeFunction {
+		for _, k := range []string{"User-Agent", "Accept-Language"} {
+			if x := v.Call("get", k); x.Type() == js.TypeString {
+				h.Set(k, x.String())
+			}
+		}
+		return h
+	}
+
+	keys := js.Global().Get("Object").Call("keys", v)
+	for i := 0; i < keys.Length(); i++ {
+		k := keys.Index(i).String()
+		if x := v.Get(k); x.Type() == js.TypeString {
+			h.Set(k, x.String())
+		}
+	}
+	return h
+}
+
+func main() {
+	parse := js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return js.Null()
+		}
+		return toJS(uaparser.Parse(args[0].String()))
+	})
+	parseHeaders := js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 1 {
+			return js.Null()
+		}
+		return toJS(uaparser.ParseHeaders(headers(args[0])))
+	})
+
+	js.Global().Set("uaparser", js.ValueOf(map[string]any{
+		"parse":        parse,
+		"parseHeaders": parseHeaders,
+	}))
+	select {}
+}
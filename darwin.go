@@ -0,0 +1,163 @@
+package uaparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// darwinRelease is the iOS (iPadOS, tvOS) and macOS release shipping a
+// darwin kernel
+type darwinRelease struct {
+	ios, macos string
+}
+
+// darwin major.minor as sent by CFNetwork apps (Darwin/20.3.0)
+var darwinReleases = map[string]darwinRelease{
+	"13.0": {"6.0", "10.9"},
+	"13.1": {"6.1", "10.9.1"},
+	"13.2": {"6.1", "10.9.2"},
+	"13.3": {"6.1", "10.9.3"},
+	"13.4": {"6.1", "10.9.5"},
+	"14.0": {"8.0", "10.10"},
+	"14.1": {"8.1", "10.10.2"},
+	"14.3": {"8.3", "10.10.3"},
+	"14.4": {"8.4", "10.10.4"},
+	"14.5": {"8.4", "10.10.5"},
+	"15.0": {"9.0", "10.11"},
+	"15.2": {"9.1", "10.11.2"},
+	"15.3": {"9.2", "10.11.3"},
+	"15.4": {"9.3", "10.11.4"},
+	"15.5": {"9.3.2", "10.11.5"},
+	"15.6": {"9.3.5", "10.11.6"},
+	"16.0": {"10.0", "10.12"},
+	"16.1": {"10.1", "10.12.1"},
+	"16.3": {"10.2", "10.12.2"},
+	"16.4": {"10.2.1", "10.12.3"},
+	"16.5": {"10.3", "10.12.4"},
+	"16.6": {"10.3.2", "10.12.5"},
+	"16.7": {"10.3.3", "10.12.6"},
+	"17.0": {"11.0", "10.13"},
+	"17.2": {"11.1", "10.13.1"},
+	"17.3": {"11.2", "10.13.2"},
+	"17.4": {"11.2.5", "10.13.3"},
+	"17.5": {"11.3", "10.13.4"},
+	"17.6": {"11.4", "10.13.5"},
+	"17.7": {"11.4.1", "10.13.6"},
+	"18.0": {"12.0", "10.14"},
+	"18.2": {"12.1", "10.14.1"},
+	"18.5": {"12.2", "10.14.4"},
+	"18.6": {"12.3", "10.14.5"},
+	"18.7": {"12.4", "10.14.6"},
+	"19.0": {"13.0", "10.15"},
+	"19.2": {"13.3", "10.15.2"},
+	"19.3": {"13.3.1", "10.15.3"},
+	"19.4": {"13.4", "10.15.4"},
+	"19.5": {"13.5", "10.15.5"},
+	"19.6": {"13.6", "10.15.6"},
+	"20.0": {"14.0", "11.0"},
+	"20.1": {"14.2", "11.0"},
+	"20.2": {"14.3", "11.1"},
+	"20.3": {"14.4", "11.2"},
+	"20.4": {"14.5", "11.3"},
+	"20.5": {"14.6", "11.4"},
+	"20.6": {"14.7", "11.5"},
+	"21.0": {"15.0", "12.0"},
+	"21.1": {"15.1", "12.0.1"},
+	"21.2": {"15.2", "12.1"},
+	"21.3": {"15.3", "12.2"},
+	"21.4": {"15.4", "12.3"},
+	"21.5": {"15.5", "12.4"},
+	"21.6": {"15.6", "12.5"},
+	"22.0": {"16.0", "13.0"},
+	"22.1": {"16.1", "13.0"},
+	"22.2": {"16.2", "13.1"},
+	"22.3": {"16.3", "13.2"},
+	"22.4": {"16.4", "13.3"},
+	"22.5": {"16.5", "13.4"},
+	"22.6": {"16.6", "13.5"},
+	"23.0": {"17.0", "14.0"},
+	"23.1": {"17.1", "14.1"},
+	"23.2": {"17.2", "14.2"},
+	"23.3": {"17.3", "14.3"},
+	"23.4": {"17.4", "14.4"},
+	"23.5": {"17.5", "14.5"},
+	"23.6": {"17.6", "14.6"},
+	"24.0": {"18.0", "15.0"},
+	"24.1": {"18.1", "15.1"},
+	"24.2": {"18.2", "15.2"},
+	"24.3": {"18.3", "15.3"},
+	"24.4": {"18.4", "15.4"},
+	"24.5": {"18.5", "15.5"},
+	"24.6": {"18.6", "15.6"},
+	"25.0": {"26.0", "26.0"},
+	"25.1": {"26.1", "26.1"},
+}
+
+// iOS 7 and 8 (and the minor releases of iOS 9) share a darwin version,
+// the CFNetwork major.minor tells them apart
+var cfnetworkReleases = map[string]string{
+	"672.0": "7.0",
+	"672.1": "7.1",
+	"711.0": "8.0",
+	"711.1": "8.1",
+	"711.2": "8.2",
+	"711.3": "8.3",
+	"711.4": "8.4",
+	"758.0": "9.0",
+	"758.1": "9.1",
+	"758.2": "9.2",
+	"758.3": "9.3",
+}
+
+// majorMinor returns 20.3 for 20.3.0
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) == 1 {
+		return parts[0] + ".0"
+	}
+	return parts[0] + "." + parts[1]
+}
+
+// lookupDarwin looks up the release of a darwin version, falling back to
+// the first release of the major version for unknown minors.
+func lookupDarwin(darwin string) (darwinRelease, bool) {
+	if r, ok := darwinReleases[majorMinor(darwin)]; ok {
+		return r, true
+	}
+	r, ok := darwinReleases[strconv.Itoa(major(darwin))+".0"]
+	return r, ok
+}
+
+// appleVersion returns the version of an Apple platform (ios, tvos, watchos,
+// visionos, macosx) from the darwin and CFNetwork versions of a CFNetwork
+// app. iPadOS follows the iOS numbering, watchOS and visionOS only get
+// their major version.
+func appleVersion(os, darwin, cfnetwork string) string {
+	r, ok := lookupDarwin(darwin)
+	if !ok {
+		return ""
+	}
+
+	switch os {
+	case "macosx":
+		return r.macos
+	case "ios", "tvos":
+		if v, ok := cfnetworkReleases[majorMinor(cfnetwork)]; ok && major(darwin) <= 15 {
+			return v
+		}
+		return r.ios
+	}
+
+	v := major(r.ios)
+	switch {
+	case v >= 26:
+	case os == "watchos":
+		v -= 7 // watchOS 2 shipped with iOS 9
+	case os == "visionos":
+		v -= 16 // visionOS 1 shipped with iOS 17
+	}
+	if v < 1 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
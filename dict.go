@@ -12,13 +12,17 @@ type psd struct {
 }
 
 var knownTags = map[string]string{
-	"ctv":        "smarttv",
-	"tablet":     "",
-	"stb":        "",
-	"cfnetwork":  "",
-	"alamofire":  "",
-	"nativehost": "",
-	"omi":        "",
+	"ctv":                   "smarttv",
+	"tablet":                "",
+	"stb":                   "",
+	"cfnetwork":             "",
+	"alamofire":             "",
+	"okhttp":                "",
+	"nativehost":            "",
+	"omi":                   "",
+	"automotive":            "",
+	"aaos":                  "automotive", // Android Automotive OS
+	"valve steam gamepadui": "gamepadui",  // Steam Big Picture, on the Deck and on desktops
 }
 
 var knownProducts = map[string]UserAgent{}
@@ -31,28 +35,71 @@ var browsers = map[string]ps{
 	"firefox":         ps{2, IN_PRODUCT},
 	"opera":           ps{2, IN_BOTH},
 	"chrome":          ps{2, IN_PRODUCT},
-	"dalvik":          ps{2, IN_PRODUCT},
+	"dalvik":          ps{2, IN_PRODUCT}, // also a client
 	"edge":            ps{3, IN_PRODUCT},
 	"silk":            ps{3, IN_BOTH},
 	"fxios":           ps{3, IN_BOTH},
+	"crios":           ps{3, IN_PRODUCT},
 	"lg browser":      ps{3, IN_PRODUCT},
-	"opr":             ps{3, IN_PRODUCT},
 	"ucbrowser":       ps{3, IN_PRODUCT},
-	"applecoremedia":  ps{2, IN_PRODUCT},
+	"applecoremedia":  ps{2, IN_PRODUCT}, // also a client
 	"leanbackshell":   ps{3, IN_PRODUCT},
 	"hbbtv":           ps{4, IN_PRODUCT},
 	"adobe primetime": ps{3, IN_PRODUCT},
 	"dmost":           ps{3, IN_PRODUCT},
+	"oculusbrowser":   ps{3, IN_PRODUCT},
+	"picobrowser":     ps{3, IN_PRODUCT},
+	"nintendobrowser": ps{3, IN_PRODUCT},
 	"youviewhtml":     ps{3, IN_PRODUCT},
 }
 
+// Chromium based browsers: Browser is the fork, UserAgent.Chromium keeps
+// the version of the Chrome token they also send
+var chromiumForks = map[string]struct {
+	name     string
+	priority int
+}{
+	"edg":                {"edge", 3},
+	"edga":               {"edge", 3},
+	"edgios":             {"edge", 3},
+	"opr":                {"opr", 3},
+	"opt":                {"opt", 3}, // opera touch
+	"samsungbrowser":     {"samsungbrowser", 3},
+	"yabrowser":          {"yabrowser", 3},
+	"vivaldi":            {"vivaldi", 3},
+	"whale":              {"whale", 3},
+	"ddg":                {"duckduckgo", 3},
+	"duckduckgo":         {"duckduckgo", 3},
+	"brave":              {"brave", 3},
+	"miuibrowser":        {"miuibrowser", 3},
+	"huaweibrowser":      {"huaweibrowser", 3},
+	"qqbrowser":          {"qqbrowser", 3},
+	"coc_coc_browser":    {"coccoc", 3},
+	"avastsecurebrowser": {"avast", 3},
+	"headlesschrome":     {"headlesschrome", 3},
+	"electron":           {"electron", 3},
+}
+
+// native HTTP stacks, a browser is not involved
+var clients = map[string]ps{
+	"cfnetwork":      ps{2, IN_PRODUCT},
+	"alamofire":      ps{2, IN_PRODUCT},
+	"okhttp":         ps{2, IN_PRODUCT},
+	"dalvik":         ps{1, IN_PRODUCT},
+	"exoplayerlib":   ps{2, IN_PRODUCT},
+	"applecoremedia": ps{2, IN_PRODUCT}, // AVPlayer
+	"curl":           ps{1, IN_PRODUCT},
+	"libcurl":        ps{1, IN_PRODUCT},
+	"java":           ps{1, IN_PRODUCT},
+}
+
 var engines = map[string]ps{
 	"applewebkit": ps{1, IN_PRODUCT},
 	"trident":     ps{1, IN_COMMENT},
 	"gecko":       ps{1, IN_PRODUCT},
 	"presto":      ps{1, IN_PRODUCT},
 
-	"exoplayerlib": ps{1, IN_PRODUCT},
+	"exoplayerlib": ps{1, IN_PRODUCT}, // also a client
 	"cobalt":       ps{1, IN_PRODUCT},
 }
 
@@ -65,6 +112,10 @@ var oses = map[string]ps{
 	"ios":        ps{2, IN_BOTH},
 	"tvos":       ps{2, IN_BOTH},
 	"rokuos":     ps{2, IN_COMMENT},
+	"steamos":    ps{2, IN_BOTH},
+	"fuchsia":    ps{2, IN_BOTH},
+	"watchos":    ps{2, IN_BOTH},
+	"visionos":   ps{2, IN_BOTH},
 }
 
 var devices = map[string]psd{
@@ -85,12 +136,35 @@ var devices = map[string]psd{
 	"xbox one": psd{2, IN_BOTH, Console},
 	"xboxone":  psd{2, IN_BOTH, Console},
 
+	"xbox_one_ed":   psd{2, IN_COMMENT, Console},
+	"xbox series x": psd{3, IN_COMMENT, Console},
+	"xbox series s": psd{3, IN_COMMENT, Console},
+
+	"playstation 5": psd{1, IN_COMMENT, Console},
 	"playstation 4": psd{1, IN_COMMENT, Console},
 	"playstation 3": psd{1, IN_COMMENT, Console},
 	"fymp":          psd{1, IN_BOTH, Console},
 
 	"nintendo switch": psd{1, IN_COMMENT, Console},
 	"nintendo wiiu":   psd{1, IN_COMMENT, Console},
+	"nintendo 3ds":    psd{1, IN_COMMENT, Console},
+
+	"new nintendo 3ds":             psd{1, IN_COMMENT, Console},
+	"new nintendo 3ds like iphone": psd{1, IN_COMMENT, Console},
+
+	"steam deck": psd{2, IN_COMMENT, Console},
+
+	"apple watch": psd{2, IN_BOTH, Wearable},
+	"garmin":      psd{1, IN_BOTH, Wearable},
+
+	"quest":            psd{2, IN_COMMENT, XR},
+	"quest 2":          psd{2, IN_COMMENT, XR},
+	"quest 3":          psd{2, IN_COMMENT, XR},
+	"quest 3s":         psd{2, IN_COMMENT, XR},
+	"quest pro":        psd{2, IN_COMMENT, XR},
+	"pacific":          psd{2, IN_COMMENT, XR}, // oculus go
+	"apple vision":     psd{2, IN_BOTH, XR},
+	"apple vision pro": psd{2, IN_BOTH, XR},
 
 	"wiiu": psd{1, IN_BOTH, Console},
 
@@ -98,23 +172,54 @@ var devices = map[string]psd{
 
 	"kindle": psd{2, IN_PRODUCT, Tablet},
 
+	"tesla": psd{2, IN_PRODUCT, Automotive},
+
+	"alexamediaplayer": psd{2, IN_PRODUCT, SmartSpeaker},
+	"sonos":            psd{2, IN_PRODUCT, SmartSpeaker},
+
+	"esp8266httpclient": psd{1, IN_PRODUCT, IoT},
+	"esp32httpclient":   psd{1, IN_PRODUCT, IoT},
+
 	"android tv":     psd{2, IN_BOTH, SmartTV},
 	"android phone":  psd{2, IN_COMMENT, Phone},
 	"android tablet": psd{2, IN_COMMENT, Tablet},
 }
 
+// Wear OS watches, matched against the start of the android device id as
+// they send no token of their own
+var wearOSModels = []string{
+	"sm-r", // galaxy watch
+	"pixel watch",
+	"moto 360",
+	"ticwatch",
+	"lg watch",
+	"huawei watch",
+	"asus zenwatch",
+	"zenwatch",
+	"sony smartwatch",
+	"fossil gen",
+	"skagen falster",
+	"oppo watch",
+	"tag heuer connected",
+}
+
+// Wear OS releases by the android major version they are built on, older
+// releases shipped on several android versions and are left out
+var wearOSVersions = map[int]string{
+	11: "3",
+	13: "4",
+	14: "5",
+	15: "5.1",
+	16: "6",
+}
+
 var skips = map[string]ps{
 	"u":           ps{0, IN_COMMENT},
 	"x11":         ps{0, IN_COMMENT},
 	"ubuntu":      ps{0, IN_COMMENT},
 	"compatible":  ps{0, IN_COMMENT},
-	"ppc":         ps{0, IN_COMMENT},
-	"arm":         ps{0, IN_COMMENT},
 	"touch":       ps{0, IN_COMMENT},
 	"macintosh":   ps{0, IN_COMMENT},
-	"x64":         ps{0, IN_COMMENT},
-	"win64":       ps{0, IN_COMMENT},
-	"wow64":       ps{0, IN_COMMENT},
 	"like gecko":  ps{0, IN_PRODUCT},
 	"like chrome": ps{0, IN_PRODUCT},
 
@@ -123,18 +228,20 @@ var skips = map[string]ps{
 	"nokia":     ps{0, IN_COMMENT},
 
 	"build": ps{0, IN_COMMENT},
+
+	"playstation": ps{0, IN_COMMENT},
 }
 
 var (
 	commentRecognizers       = make(map[string]*recognizer)
 	productRecognizers       = make(map[string]*recognizer)
-	commentPrefixRecognizers []*recognizer
-	productPrefixRecognizers []*recognizer
+	commentPrefixRecognizers = newPrefixTrie()
+	productPrefixRecognizers = newPrefixTrie()
 )
 
 func addPrefix(source int, prefix string, name string, priority int, deviceType int, handler func(*UserAgent, *recognizer, *section) bool) {
 	if (source & IN_COMMENT) != 0 {
-		commentPrefixRecognizers = append(commentPrefixRecognizers, &recognizer{
+		commentPrefixRecognizers.add(&recognizer{
 			prefix:     prefix,
 			rewrite:    name,
 			priority:   priority,
@@ -143,7 +250,7 @@ func addPrefix(source int, prefix string, name string, priority int, deviceType
 		})
 	}
 	if (source & IN_PRODUCT) != 0 {
-		productPrefixRecognizers = append(productPrefixRecognizers, &recognizer{
+		productPrefixRecognizers.add(&recognizer{
 			prefix:     prefix,
 			rewrite:    name,
 			priority:   priority,
@@ -172,6 +279,10 @@ func init() {
 		}
 	}
 
+	for k, v := range chromiumForks {
+		productRecognizers[k] = &recognizer{typ: BROWSER, priority: v.priority, rewrite: v.name}
+	}
+
 	for k, v := range engines {
 		if (v.source & IN_PRODUCT) != 0 {
 			productRecognizers[k] = &recognizer{typ: ENGINE, priority: v.priority}
@@ -181,6 +292,15 @@ func init() {
 		}
 
 	}
+	for k, v := range clients {
+		if (v.source & IN_PRODUCT) != 0 {
+			productRecognizers[k] = &recognizer{typ: CLIENT, priority: v.priority, also: productRecognizers[k]}
+		}
+		if (v.source & IN_COMMENT) != 0 {
+			commentRecognizers[k] = &recognizer{typ: CLIENT, priority: v.priority, also: commentRecognizers[k]}
+		}
+	}
+
 	for k, v := range devices {
 		if (v.source & IN_PRODUCT) != 0 {
 			productRecognizers[k] = &recognizer{typ: DEVICE, priority: v.priority, deviceType: v.deviceType}
@@ -191,8 +311,16 @@ func init() {
 
 	}
 
+	// a bare language (en) is only a locale after the U security token,
+	// which the comment loop checks
 	for _, v := range languages {
-		commentRecognizers[v] = &recognizer{typ: LANGUAGE}
+		if len(v) > 3 {
+			commentRecognizers[v] = &recognizer{typ: LANGUAGE}
+		}
+	}
+
+	for k := range archs {
+		commentRecognizers[k] = &recognizer{typ: ARCH}
 	}
 
 	for k, v := range skips {
@@ -205,6 +333,9 @@ func init() {
 
 	}
 
+	// CrKey is a chromecast unless DeviceType says otherwise, priority above crkey
+	productRecognizers["devicetype"] = &recognizer{typ: DEVICE, priority: 3, handler: handle_cast_device_type}
+
 	addPrefix(IN_COMMENT, "windows nt ", "windows_nt", 1, 0, handle_os_version)
 	addPrefix(IN_COMMENT, "linux ", "linux", 1, 0, handle_os_version)
 	addPrefix(IN_COMMENT, "android ", "android", 2, 0, handle_os_version)
@@ -213,17 +344,23 @@ func init() {
 	addPrefix(IN_COMMENT, "intel mac os x ", "macosx", 1, 0, handle_os_version)
 	addPrefix(IN_COMMENT, "cros ", "chromeos", 1, 0, handle_os_version)
 	addPrefix(IN_COMMENT, "tizen", "tizen", 2, 0, handle_os_version)
+	addPrefix(IN_COMMENT, "wear os", "wearos", 2, 0, handle_os_version)
 
 	addPrefix(IN_COMMENT, "crkey ", "chromecast", 1, SmartTV, handle_device_version)
 	addPrefix(IN_COMMENT, "apple tv", "appletv", 1, SmartTV, handle_device_version)
 
+	addPrefix(IN_COMMENT, "playstation 5", "ps5", 1, Console, handle_device_version)
 	addPrefix(IN_COMMENT, "playstation 4", "ps4", 1, Console, handle_device_version)
 	addPrefix(IN_COMMENT, "playstation 3", "ps3", 1, Console, handle_device_version)
+	addPrefix(IN_COMMENT, "playstation vita", "psvita", 1, Console, handle_device_version)
 
 	addPrefix(IN_PRODUCT, "roku ", "roku", 2, SmartTV, handle_device_version)
 	addPrefix(IN_PRODUCT, "rokudvp-", "roku", 2, SmartTV, handle_device_version)
+	addPrefix(IN_PRODUCT, "roku", "roku", 2, SmartTV, handle_roku)
 	addPrefix(IN_COMMENT, "googletv ", "googletv", 2, SmartTV, handle_device_version)
 
+	addPrefix(IN_COMMENT, "pico ", "pico", 2, XR, handle_device_version)
+
 	addPrefix(IN_COMMENT, "iphone", "iphone", 2, Phone, handle_device_version)
 	addPrefix(IN_COMMENT, "ipod", "ipod", 2, Phone, handle_device_version)
 	addPrefix(IN_COMMENT, "ipad", "ipad", 2, Tablet, handle_device_version)
@@ -242,4 +379,9 @@ func init() {
 	addPrefix(IN_COMMENT, "cpu tvos ", "tvos", 1, 0, handle_ios)
 	addPrefix(IN_COMMENT, "ios ", "", 1, 0, handle_ios)
 	addPrefix(IN_COMMENT, "tvos ", "tvos", 1, 0, handle_ios)
+	addPrefix(IN_COMMENT, "cpu watch os ", "watchos", 1, 0, handle_ios)
+	addPrefix(IN_COMMENT, "watchos ", "watchos", 1, 0, handle_ios)
+	addPrefix(IN_COMMENT, "cpu visionos ", "visionos", 1, 0, handle_ios)
+	addPrefix(IN_COMMENT, "visionos ", "visionos", 1, 0, handle_ios)
+	addPrefix(IN_COMMENT, "xros ", "visionos", 1, 0, handle_ios)
 }
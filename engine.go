@@ -0,0 +1,79 @@
+package uaparser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// browser families that are not chromium based, everything carrying a
+// Chrome token is chromium
+var browserFamilies = map[string]string{
+	"safari":        "safari",
+	"mobile safari": "safari",
+	"tv safari":     "safari",
+	"firefox":       "firefox",
+	"fxios":         "firefox",
+	"crios":         "chromium", // no Chrome token on iOS
+	"msie":          "ie",
+	"opera":         "opera",
+	"edge":          "edge",
+}
+
+// apple requires WebKit for every browser on these
+var webkitOnly = map[string]bool{
+	"ios":      true,
+	"tvos":     true,
+	"watchos":  true,
+	"visionos": true,
+}
+
+func major(version string) int {
+	if i := strings.IndexByte(version, '.'); i >= 0 {
+		version = version[:i]
+	}
+	n, _ := strconv.Atoi(version)
+	return n
+}
+
+// deriveEngine reports the rendering engine actually in use rather than the
+// compatibility token (Chrome sends AppleWebKit/537.36), and groups the
+// browser into its family.
+func (ua *UserAgent) deriveEngine() {
+	if ua.Browser.Name != "" {
+		ua.Browser.Family = browserFamilies[ua.Browser.Name]
+	}
+
+	// renaming the compatibility token keeps its provenance, an engine
+	// implied by the OS or browser alone is a guess
+	derive := func(name string) {
+		p := ua.Engine.Provenance
+		if p == NotDetected {
+			p = Heuristic
+		}
+		ua.Engine.set(name, p)
+	}
+
+	switch {
+	case webkitOnly[ua.OS.Name]:
+		if ua.Browser.Name != "" || ua.Engine.Name != "" {
+			derive("webkit")
+		}
+	case ua.Browser.Name == "edge" && major(ua.Browser.Version) >= 12 && major(ua.Browser.Version) <= 18:
+		// legacy edge sends a Chrome token too
+		derive("edgehtml")
+		ua.Engine.Version = ua.Browser.Version
+	case ua.Chromium != "":
+		ua.Browser.Family = "chromium"
+		if major(ua.Chromium) >= 28 {
+			derive("blink")
+			ua.Engine.Version = ua.Chromium
+		} else {
+			derive("webkit")
+		}
+	case ua.Engine.Name == "applewebkit":
+		derive("webkit")
+	case ua.Engine.Name == "gecko" && ua.rv != "":
+		// Gecko/20100101 is frozen, rv: is the real version
+		ua.Engine.Version = ua.rv
+	}
+}
@@ -0,0 +1,58 @@
+package uaparser
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// IsMobile reports whether the UA carries a Mobile token or the device is a
+// phone or tablet.
+func (ua *UserAgent) IsMobile() bool {
+	return ua.mobile || ua.DeviceType == Phone || ua.DeviceType == Tablet
+}
+
+// IsWebView reports whether the UA comes from an Android WebView ("; wv)").
+func (ua *UserAgent) IsWebView() bool {
+	return ua.webview
+}
+
+// IsNativeApp reports whether the UA comes from a native HTTP stack
+// (CFNetwork, Alamofire, OkHttp, ...) rather than a browser.
+func (ua *UserAgent) IsNativeApp() bool {
+	return ua.Client.Name != "" || ua.HasTag("nativehost")
+}
+
+// IsConnectedTV reports whether the device is a smart TV or announces
+// itself as a connected TV.
+func (ua *UserAgent) IsConnectedTV() bool {
+	return ua.DeviceType == SmartTV || ua.HasTag("smarttv")
+}
+
+// IsSetTopBox reports whether the device is a set-top box.
+func (ua *UserAgent) IsSetTopBox() bool {
+	return ua.DeviceType == SetTop || ua.HasTag("stb")
+}
+
+// HasTag reports whether the UA carries one of the tokens in knownTags,
+// e.g. "ctv", "tablet" or "cfnetwork".
+func (ua *UserAgent) HasTag(name string) bool {
+	name = strings.ToLower(name)
+	if t, ok := knownTags[name]; ok && t != "" {
+		name = t
+	}
+	_, ok := ua.tags[name]
+	return ok
+}
+
+// MarshalJSON encodes the exported fields together with the flags above
+func (ua *UserAgent) MarshalJSON() ([]byte, error) {
+	type fields UserAgent // without the MarshalJSON method
+	return json.Marshal(struct {
+		*fields
+		Mobile      bool `json:"mobile"`
+		WebView     bool `json:"webview"`
+		NativeApp   bool `json:"native_app"`
+		ConnectedTV bool `json:"connected_tv"`
+		SetTopBox   bool `json:"set_top_box"`
+	}{(*fields)(ua), ua.IsMobile(), ua.IsWebView(), ua.IsNativeApp(), ua.IsConnectedTV(), ua.IsSetTopBox()})
+}
@@ -0,0 +1,100 @@
+package uaparser
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// silence swaps os.Stdout for a file while f runs and reports whether f
+// wrote to it
+func silence(t *testing.T, out *os.File, f func()) bool {
+	stdout := os.Stdout
+	os.Stdout = out
+	defer func() { os.Stdout = stdout }()
+
+	before, err := out.Seek(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f()
+	after, err := out.Seek(0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return after != before
+}
+
+func fuzzSeeds(f *testing.F) {
+	for _, s := range []string{
+		"",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 13_3_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBLC/en_US]",
+		"Mozilla/5.0 (Linux; Android 11; Pixel 5 Build/RQ3A.210805.001.A1; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/92.0.4515.159 Mobile Safari/537.36",
+		"AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0",
+		"Roku4640X/DVP-7.70 (297.70E04154A)",
+		"((()))[[]]",
+		"a (b (c) d) e [f [g] h] i",
+		"Mozilla/5.0 (Linux; Android 10",
+		"\xff\xfeMozilla/\xc3(",
+	} {
+		f.Add(s)
+	}
+}
+
+// FuzzParse checks that Parse does not panic or print, does not depend on
+// the case of its input, and agrees with Parser and ParseHeaders.
+func FuzzParse(f *testing.F) {
+	fuzzSeeds(f)
+	out, err := os.CreateTemp(f.TempDir(), "stdout")
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer out.Close()
+	parser := NewParser()
+
+	f.Fuzz(func(t *testing.T, s string) {
+		var ua *UserAgent
+		if silence(t, out, func() { ua = Parse(s) }) {
+			t.Errorf("%q: Parse wrote to stdout\n", s)
+		}
+		if _, err := json.Marshal(ua); err != nil {
+			t.Errorf("%q: %v\n", s, err)
+		}
+
+		// ToLower is not idempotent for a few runes, Parse lower cases once
+		if lower := strings.ToLower(s); strings.ToLower(lower) == lower {
+			if x := Parse(lower); !reflect.DeepEqual(ua, x) {
+				t.Errorf("%q: expected: %+v, got: %+v for the lower cased input\n", s, ua, x)
+			}
+		}
+
+		if x := parser.Parse(s); x.ShortName() != ua.ShortName() || x.OS != ua.OS || x.Browser != ua.Browser || x.Device != ua.Device {
+			t.Errorf("%q: Parse: %+v, Parser.Parse: %+v\n", s, ua, x)
+		}
+		if x := ParseHeaders(http.Header{"User-Agent": {s}}); x.ShortName() != ua.ShortName() || x.OS != ua.OS || x.Browser != ua.Browser {
+			t.Errorf("%q: Parse: %+v, ParseHeaders: %+v\n", s, ua, x)
+		}
+	})
+}
+
+// Fuzz_parse checks the tokenizer on its own. mergeItems is local to
+// parseWith, FuzzParse covers it.
+func Fuzz_parse(f *testing.F) {
+	fuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, s string) {
+		for _, item := range parse(s) {
+			switch x := item.(type) {
+			case *section:
+				if x.name != strings.TrimSpace(x.name) {
+					t.Errorf("%q: untrimmed section %q\n", s, x.name)
+				}
+			case comment, *skip:
+			default:
+				t.Errorf("%q: unexpected item %#v\n", s, item)
+			}
+		}
+	})
+}
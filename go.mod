@@ -1 +1,3 @@
 module github.com/jdeng/uaparser
+
+go 1.18
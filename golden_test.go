@@ -0,0 +1,155 @@
+package uaparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/golden.jsonl with the current output")
+
+const goldenFile = "testdata/golden.jsonl"
+
+// golden is one line of testdata/golden.jsonl. The UAs are real ones
+// collected by other parsers, Source names where from:
+//
+//	uap-core          github.com/ua-parser/uap-core v0.18.0, tests/test_ua.yaml and
+//	                  test_os.yaml, every tenth case of test_device.yaml (Apache 2.0)
+//	uap-go            github.com/ua-parser/uap-go, uas (Apache 2.0)
+//	projectdiscovery  github.com/projectdiscovery/useragent, useragent_data.json (MIT)
+//	mssola            github.com/mssola/user_agent, all_test.go (MIT)
+//	mileusna          github.com/mileusna/useragent, ua_test.go (MIT)
+type golden struct {
+	UA       string          `json:"ua"`
+	Source   string          `json:"source,omitempty"`
+	Expected json.RawMessage `json:"expected"`
+}
+
+// flatten turns {"os":{"name":"ios"}} into os.name: ios
+func flatten(prefix string, v interface{}, fields map[string]string) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, v := range x {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flatten(k, v, fields)
+		}
+	case nil:
+	default:
+		fields[prefix] = fmt.Sprint(x)
+	}
+}
+
+func fieldsOf(b []byte) (map[string]string, error) {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]string)
+	flatten("", v, fields)
+	return fields, nil
+}
+
+func readGolden(t *testing.T) []golden {
+	f, err := os.Open(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var corpus []golden
+	in := bufio.NewScanner(f)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; in.Scan(); n++ {
+		var g golden
+		if err := json.Unmarshal(in.Bytes(), &g); err != nil {
+			t.Fatalf("%s:%d: %v", goldenFile, n, err)
+		}
+		corpus = append(corpus, g)
+	}
+	if err := in.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return corpus
+}
+
+func writeGolden(t *testing.T, corpus []golden) {
+	f, err := os.Create(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, g := range corpus {
+		g.Expected, _ = json.Marshal(Parse(g.UA))
+		if err := enc.Encode(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestGolden compares Parse with the expected output of every UA in
+// testdata/golden.jsonl and reports the accuracy per field. Run
+// go test -run Golden -update to accept the current output, the diff of
+// the golden file then shows how a rule change reclassifies the corpus.
+func TestGolden(t *testing.T) {
+	corpus := readGolden(t)
+	if *update {
+		writeGolden(t, corpus)
+		return
+	}
+
+	type stat struct{ total, ok int }
+	stats := make(map[string]*stat)
+	for i, g := range corpus {
+		expected, err := fieldsOf(g.Expected)
+		if err != nil {
+			t.Fatalf("%d: %s: %v", i, g.UA, err)
+		}
+		b, _ := json.Marshal(Parse(g.UA))
+		got, _ := fieldsOf(b)
+
+		keys := make(map[string]bool)
+		for k := range expected {
+			keys[k] = true
+		}
+		for k := range got {
+			keys[k] = true
+		}
+		for k := range keys {
+			s := stats[k]
+			if s == nil {
+				s = &stat{}
+				stats[k] = s
+			}
+			s.total++
+			if expected[k] == got[k] {
+				s.ok++
+			} else {
+				t.Errorf("%d: %s, %s expected: %q, got: %q\n", i, g.UA, k, expected[k], got[k])
+			}
+		}
+	}
+
+	names := make([]string, 0, len(stats))
+	for k := range stats {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		s := stats[k]
+		t.Logf("%-24s %6.2f%% (%d/%d)", k, 100*float64(s.ok)/float64(s.total), s.ok, s.total)
+	}
+}
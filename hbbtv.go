@@ -0,0 +1,76 @@
+package uaparser
+
+import (
+	"strings"
+)
+
+// HbbTV holds the fields of the standardized HbbTV user agent tail:
+// HbbTV/1.2.1 (<capabilities>;<vendor>;<model>;<software>;<hardware>;<family>)
+type HbbTV struct {
+	Version         string   `json:"version"`
+	Capabilities    []string `json:"capabilities,omitempty"` // drm, pvr, tva, dl, ...
+	Vendor          string   `json:"vendor,omitempty"`
+	Model           string   `json:"model,omitempty"`
+	SoftwareVersion string   `json:"software_version,omitempty"`
+	HardwareVersion string   `json:"hardware_version,omitempty"`
+	FamilyName      string   `json:"family_name,omitempty"`
+}
+
+// Has reports whether the terminal announced a capability, e.g. "drm"
+func (h *HbbTV) Has(capability string) bool {
+	capability = strings.TrimPrefix(strings.ToLower(capability), "+")
+	for _, x := range h.Capabilities {
+		if x == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHbbTV reads the HbbTV tail from a lower cased UA, before "+" is
+// replaced, as the capabilities are "+" separated and the fields are
+// positional (parseComment would drop the empty ones).
+// HbbTV/1.1.1 (;Panasonic;VIERA 2011;1.261;0071-3103 2000-0000;)
+func parseHbbTV(s string) *HbbTV {
+	i := strings.Index(s, "hbbtv/")
+	if i < 0 {
+		return nil
+	}
+	s = s[i+len("hbbtv/"):]
+
+	h := &HbbTV{}
+	end := strings.IndexAny(s, " (")
+	if end < 0 {
+		h.Version = s
+		return h
+	}
+	h.Version = s[:end]
+
+	s = strings.TrimLeft(s[end:], " ")
+	if !strings.HasPrefix(s, "(") {
+		return h
+	}
+	if end = strings.IndexByte(s, ')'); end < 0 {
+		return h
+	}
+
+	fields := strings.Split(s[1:end], ";")
+	for len(fields) < 6 {
+		fields = append(fields, "")
+	}
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	for _, x := range strings.Split(fields[0], "+") {
+		if x = strings.TrimSpace(x); x != "" {
+			h.Capabilities = append(h.Capabilities, x)
+		}
+	}
+	h.Vendor = fields[1]
+	h.Model = fields[2]
+	h.SoftwareVersion = fields[3]
+	h.HardwareVersion = fields[4]
+	h.FamilyName = fields[5]
+	return h
+}
@@ -0,0 +1,41 @@
+// Package corpus reads the benchmark corpus shared by BenchmarkParse and
+// cmd/uabench, category<TAB>user agent per line. Empty lines and lines
+// starting with # are skipped.
+package corpus
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// Read returns the categories in the order they first appear and the user
+// agents of each
+func Read(name string) ([]string, map[string][]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var categories []string
+	corpus := make(map[string][]string)
+	in := bufio.NewScanner(f)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+	for in.Scan() {
+		line := in.Text()
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, '\t')
+		if i <= 0 {
+			continue
+		}
+		c := line[:i]
+		if _, ok := corpus[c]; !ok {
+			categories = append(categories, c)
+		}
+		corpus[c] = append(corpus[c], line[i+1:])
+	}
+	return categories, corpus, in.Err()
+}
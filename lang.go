@@ -62,3 +62,11 @@ var languages = []string{
 	"zh", "zh-cn", "zh-hk", "zh-sg", "zh-tw",
 	"zu",
 }
+
+// primary language subtags accepted in locales outside the list above
+var moreLanguages = []string{
+	"am", "as", "az", "bn", "bs", "cy", "eo", "fil", "fy", "gl", "gu", "ha", "haw", "hy", "ig", "iu",
+	"jv", "ka", "kk", "km", "kn", "ky", "lb", "lo", "mi", "ml", "mn", "mr", "my", "nb", "ne", "nn",
+	"or", "pa", "ps", "qu", "rw", "sd", "si", "so", "sw", "ta", "te", "tg", "tk", "tl", "tt", "ug",
+	"uz", "wo", "yi", "yo", "yue",
+}
@@ -1,2 +1,14 @@
-go build -o libuap.a -buildmode=c-archive main.go
-gcc -o test main.c libuap.a
+#!/bin/sh
+set -e
+cd "$(dirname "$0")"
+
+case "$(uname)" in
+Darwin) so=libuap.dylib ;;
+*) so=libuap.so ;;
+esac
+
+go build -o libuap.a -buildmode=c-archive .
+gcc -Wall -o example/uap example/main.c libuap.a -lpthread
+
+# shared library for the python and node bindings
+go build -o $so -buildmode=c-shared .
@@ -0,0 +1,49 @@
+#include <stdio.h>
+#include <stdlib.h>
+
+#include "../uap.h"
+
+int main(int argc, const char *argv[]) {
+	const char *s = "Mozilla/5.0 (Linux; U; en-US) AppleWebKit/528.5+ (KHTML, like Gecko, Safari/528.5+) Version/4.0 Kindle/3.0 (screen 600×800; rotate)";
+	if (argc > 1) s = argv[1];
+
+	uap_result r;
+	int err = uap_parse(0, s, &r);
+	if (err != UAP_OK) {
+		fprintf(stderr, "uap_parse: %s\n", uap_strerror(err));
+		return 1;
+	}
+	printf("input: %s\ndevice: %d %s %s\nos: %s %s\nbrowser: %s %s\n", s, r.device_type, r.device.brand, r.device.name, r.os.name, r.os.version, r.browser.name, r.browser.version);
+	printf("locale: %s (%s %s %s)\narch: %s %d on %s\n", r.language, r.locale_language, r.locale_script, r.locale_region, r.arch, r.bits, r.os_arch);
+	uap_result_free(&r);
+
+	uap_parser p = uap_parser_new();
+	if ((err = uap_parser_add_rule(p, "kindle", UAP_RULE_DEVICE, 3, UAP_IN_PRODUCT, UAP_DEVICE_TABLET)) != UAP_OK) {
+		fprintf(stderr, "uap_parser_add_rule: %s\n", uap_strerror(err));
+		return 1;
+	}
+
+	const char *batch[] = {s, "AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0"};
+	uap_result rs[2];
+	if ((err = uap_parse_batch(p, batch, 2, rs)) != UAP_OK) {
+		fprintf(stderr, "uap_parse_batch: %s\n", uap_strerror(err));
+		return 1;
+	}
+	for (int i = 0; i < 2; i++) {
+		printf("batch %d: %d %s %s %s\n", i, rs[i].device_type, rs[i].device.name, rs[i].os.name, rs[i].os.version);
+		uap_result_free(&rs[i]);
+	}
+
+	char *json;
+	if (uap_parse_json(p, s, &json) == UAP_OK) {
+		printf("json: %s\n", json);
+		uap_free(json);
+	}
+	uap_parser_free(p);
+
+	if ((err = uap_parse(p, s, &r)) != UAP_ERR_HANDLE) {
+		fprintf(stderr, "expected UAP_ERR_HANDLE, got %d\n", err);
+		return 1;
+	}
+	return 0;
+}
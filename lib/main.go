@@ -2,11 +2,209 @@ package main
 
 /*
 #include <stdlib.h>
+
+#define UAP_NO_PROTOTYPES
+#include "uap.h"
 */
 import "C"
 
-import "unsafe"
-import "github.com/jdeng/uaparser"
+import (
+	"encoding/json"
+	"sync"
+	"unsafe"
+
+	"github.com/jdeng/uaparser"
+)
+
+// the constants of uap.h and the Go ones they are passed as, main_test.go
+// checks that they agree
+var abi = []struct {
+	name string
+	c, g int
+}{
+	{"UAP_DEVICE_UNKNOWN", C.UAP_DEVICE_UNKNOWN, uaparser.UnknownDevice},
+	{"UAP_DEVICE_PHONE", C.UAP_DEVICE_PHONE, uaparser.Phone},
+	{"UAP_DEVICE_TABLET", C.UAP_DEVICE_TABLET, uaparser.Tablet},
+	{"UAP_DEVICE_SMARTTV", C.UAP_DEVICE_SMARTTV, uaparser.SmartTV},
+	{"UAP_DEVICE_SETTOP", C.UAP_DEVICE_SETTOP, uaparser.SetTop},
+	{"UAP_DEVICE_CONSOLE", C.UAP_DEVICE_CONSOLE, uaparser.Console},
+	{"UAP_DEVICE_DESKTOP", C.UAP_DEVICE_DESKTOP, uaparser.Desktop},
+	{"UAP_DEVICE_WEARABLE", C.UAP_DEVICE_WEARABLE, uaparser.Wearable},
+	{"UAP_DEVICE_XR", C.UAP_DEVICE_XR, uaparser.XR},
+	{"UAP_DEVICE_AUTOMOTIVE", C.UAP_DEVICE_AUTOMOTIVE, uaparser.Automotive},
+	{"UAP_DEVICE_SMARTSPEAKER", C.UAP_DEVICE_SMARTSPEAKER, uaparser.SmartSpeaker},
+	{"UAP_DEVICE_SMARTDISPLAY", C.UAP_DEVICE_SMARTDISPLAY, uaparser.SmartDisplay},
+	{"UAP_DEVICE_IOT", C.UAP_DEVICE_IOT, uaparser.IoT},
+
+	{"UAP_RULE_OS", C.UAP_RULE_OS, uaparser.OS},
+	{"UAP_RULE_BROWSER", C.UAP_RULE_BROWSER, uaparser.BROWSER},
+	{"UAP_RULE_DEVICE", C.UAP_RULE_DEVICE, uaparser.DEVICE},
+	{"UAP_RULE_ENGINE", C.UAP_RULE_ENGINE, uaparser.ENGINE},
+	{"UAP_RULE_SKIP", C.UAP_RULE_SKIP, uaparser.SKIP},
+	{"UAP_RULE_CLIENT", C.UAP_RULE_CLIENT, uaparser.CLIENT},
+
+	{"UAP_IN_PRODUCT", C.UAP_IN_PRODUCT, uaparser.IN_PRODUCT},
+	{"UAP_IN_COMMENT", C.UAP_IN_COMMENT, uaparser.IN_COMMENT},
+	{"UAP_IN_BOTH", C.UAP_IN_BOTH, uaparser.IN_BOTH},
+}
+
+// parser handles, 0 is the built-in rules
+var handles = struct {
+	sync.RWMutex
+	next    C.uap_parser
+	parsers map[C.uap_parser]*uaparser.Parser
+}{parsers: make(map[C.uap_parser]*uaparser.Parser)}
+
+func lookup(p C.uap_parser) (*uaparser.Parser, bool) {
+	if p == 0 {
+		return nil, true
+	}
+	handles.RLock()
+	defer handles.RUnlock()
+	parser, ok := handles.parsers[p]
+	return parser, ok
+}
+
+func parse(p *uaparser.Parser, s *C.char) *uaparser.UserAgent {
+	if p == nil {
+		return uaparser.Parse(C.GoString(s))
+	}
+	return p.Parse(C.GoString(s))
+}
+
+func boolInt(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func setComponent(dst *C.uap_component, c *uaparser.Component) {
+	dst.name = C.CString(c.Name)
+	dst.version = C.CString(c.Version)
+	dst.build = C.CString(c.Build)
+	dst.brand = C.CString(c.Brand)
+	dst.family = C.CString(c.Family)
+}
+
+func setResult(dst *C.uap_result, ua *uaparser.UserAgent) {
+	dst.device_type = C.int(ua.DeviceType)
+	setComponent(&dst.os, &ua.OS)
+	setComponent(&dst.browser, &ua.Browser)
+	setComponent(&dst.device, &ua.Device)
+	setComponent(&dst.engine, &ua.Engine)
+	setComponent(&dst.client, &ua.Client)
+	setComponent(&dst.kernel, &ua.Kernel)
+	dst.language = C.CString(ua.Language)
+	dst.locale_language = C.CString(ua.Locale.Language)
+	dst.locale_script = C.CString(ua.Locale.Script)
+	dst.locale_region = C.CString(ua.Locale.Region)
+	dst.chromium = C.CString(ua.Chromium)
+	dst.arch = C.CString(ua.Arch)
+	dst.bits = C.int(ua.Bits)
+	dst.os_arch = C.CString(ua.OSArch)
+	dst.tv_brand = C.CString(ua.TV.Brand)
+	dst.tv_platform = C.CString(ua.TV.Platform)
+	dst.tv_year = C.int(ua.TV.Year)
+	dst.mobile = boolInt(ua.IsMobile())
+	dst.webview = boolInt(ua.IsWebView())
+	dst.native_app = boolInt(ua.IsNativeApp())
+	dst.connected_tv = boolInt(ua.IsConnectedTV())
+	dst.set_top_box = boolInt(ua.IsSetTopBox())
+}
+
+//export uap_parse
+func uap_parse(p C.uap_parser, s *C.char, out *C.uap_result) C.int {
+	if s == nil || out == nil {
+		return C.UAP_ERR_NULL
+	}
+	parser, ok := lookup(p)
+	if !ok {
+		return C.UAP_ERR_HANDLE
+	}
+	setResult(out, parse(parser, s))
+	return C.UAP_OK
+}
+
+//export uap_parse_batch
+func uap_parse_batch(p C.uap_parser, uas **C.char, n C.size_t, out *C.uap_result) C.int {
+	if n == 0 {
+		return C.UAP_OK
+	}
+	if uas == nil || out == nil {
+		return C.UAP_ERR_NULL
+	}
+	parser, ok := lookup(p)
+	if !ok {
+		return C.UAP_ERR_HANDLE
+	}
+
+	in := unsafe.Slice(uas, int(n))
+	results := unsafe.Slice(out, int(n))
+	for _, s := range in {
+		if s == nil {
+			return C.UAP_ERR_NULL
+		}
+	}
+	for i, s := range in {
+		setResult(&results[i], parse(parser, s))
+	}
+	return C.UAP_OK
+}
+
+//export uap_parse_json
+func uap_parse_json(p C.uap_parser, s *C.char, out **C.char) C.int {
+	if s == nil || out == nil {
+		return C.UAP_ERR_NULL
+	}
+	parser, ok := lookup(p)
+	if !ok {
+		return C.UAP_ERR_HANDLE
+	}
+
+	b, _ := json.Marshal(parse(parser, s))
+	*out = C.CString(string(b))
+	return C.UAP_OK
+}
+
+//export uap_parser_new
+func uap_parser_new() C.uap_parser {
+	handles.Lock()
+	defer handles.Unlock()
+	handles.next++
+	handles.parsers[handles.next] = uaparser.NewParser()
+	return handles.next
+}
+
+//export uap_parser_free
+func uap_parser_free(p C.uap_parser) {
+	handles.Lock()
+	defer handles.Unlock()
+	delete(handles.parsers, p)
+}
+
+//export uap_parser_add_rule
+func uap_parser_add_rule(p C.uap_parser, token *C.char, typ, priority, source, deviceType C.int) C.int {
+	if token == nil {
+		return C.UAP_ERR_NULL
+	}
+	parser, ok := lookup(p)
+	if !ok || parser == nil {
+		return C.UAP_ERR_HANDLE
+	}
+
+	err := parser.AddRule(uaparser.Rule{
+		Token:      C.GoString(token),
+		Type:       int(typ),
+		Priority:   int(priority),
+		Source:     int(source),
+		DeviceType: int(deviceType),
+	})
+	if err != nil {
+		return C.UAP_ERR_RULE
+	}
+	return C.UAP_OK
+}
 
 //export ParseUserAgent
 func ParseUserAgent(s *C.char) *C.char {
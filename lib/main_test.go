@@ -0,0 +1,13 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestABI(t *testing.T) {
+	for i, x := range abi {
+		if x.c != x.g {
+			t.Errorf("%d: %s, expected: %d, got: %d\n", i, x.name, x.g, x.c)
+		}
+	}
+}
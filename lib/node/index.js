@@ -0,0 +1,105 @@
+// Node binding of libuap, the C API of uaparser, over koffi (ffi).
+// Results are the JSON of the Go UserAgent, so they match what the Go
+// parser returns field by field. Empty fields such as conflicts, language
+// or arch are left out, as in the Go JSON:
+//
+//   const uap = require('uaparser');
+//   const ua = uap.parse('AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0');
+//   ua.os
+//   // { name: 'ios', version: '14.4', provenance: 'heuristic' }
+//   ua.client
+//   // { name: 'cfnetwork', version: '1220.1', provenance: 'token' }
+//   [ua.device_type, ua.device_type_provenance]
+//   // [ 1, 'default' ]
+//
+// The library is looked up in $UAP_LIBRARY, then next to this directory
+// (lib/libuap.so as built by lib/build.sh).
+'use strict';
+
+const path = require('path');
+const koffi = require('koffi');
+
+const UAP_OK = 0;
+
+const lib = koffi.load(process.env.UAP_LIBRARY ||
+	path.join(__dirname, '..', process.platform === 'darwin' ? 'libuap.dylib' : 'libuap.so'));
+
+const uap_parse_json = lib.func('int uap_parse_json(uintptr_t p, const char *ua, _Out_ void **json)');
+const uap_free = lib.func('void uap_free(void *ptr)');
+const uap_parser_new = lib.func('uintptr_t uap_parser_new(void)');
+const uap_parser_free = lib.func('void uap_parser_free(uintptr_t p)');
+const uap_parser_add_rule = lib.func('int uap_parser_add_rule(uintptr_t p, const char *token, int type, int priority, int source, int device_type)');
+const uap_strerror = lib.func('const char *uap_strerror(int err)');
+
+class UapError extends Error {
+	constructor(code) {
+		super(uap_strerror(code));
+		this.code = code;
+	}
+}
+
+function parseWith(handle, ua) {
+	const out = [null];
+	const err = uap_parse_json(handle, ua, out);
+	if (err !== UAP_OK) {
+		throw new UapError(err);
+	}
+	try {
+		return JSON.parse(koffi.decode(out[0], 'char', -1));
+	} finally {
+		uap_free(out[0]);
+	}
+}
+
+// Parser carries custom rules on top of the built-in tables
+class Parser {
+	constructor() {
+		this.handle = uap_parser_new();
+	}
+
+	// handle 0 is the built-in parser, never fall back to it once closed
+	check() {
+		if (this.handle === null) {
+			throw new Error('parser is closed');
+		}
+		return this.handle;
+	}
+
+	addRule(token, type, { priority = 1, source = IN_PRODUCT, deviceType = 0 } = {}) {
+		const err = uap_parser_add_rule(this.check(), token, type, priority, source, deviceType);
+		if (err !== UAP_OK) {
+			throw new UapError(err);
+		}
+	}
+
+	parse(ua) {
+		return parseWith(this.check(), ua);
+	}
+
+	parseBatch(uas) {
+		const handle = this.check();
+		return uas.map((ua) => parseWith(handle, ua));
+	}
+
+	close() {
+		if (this.handle !== null) {
+			uap_parser_free(this.handle);
+			this.handle = null;
+		}
+	}
+}
+
+// rule types
+const OS = 1, BROWSER = 2, DEVICE = 3, ENGINE = 4, SKIP = 6, CLIENT = 8;
+
+// rule sources
+const IN_PRODUCT = 0x01, IN_COMMENT = 0x02, IN_BOTH = 0x03;
+
+module.exports = {
+	parse: (ua) => parseWith(0, ua),
+	parseBatch: (uas) => uas.map((ua) => parseWith(0, ua)),
+	Parser,
+	UapError,
+	OS, BROWSER, DEVICE, ENGINE, SKIP, CLIENT,
+	IN_PRODUCT, IN_COMMENT, IN_BOTH,
+};
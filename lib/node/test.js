@@ -0,0 +1,43 @@
+// Tests of the koffi binding against the built library (lib/build.sh):
+//
+//   npm install && npm test
+'use strict';
+
+const test = require('node:test');
+const assert = require('node:assert');
+const uap = require('./index.js');
+
+const UA = 'MyApp/2.1 (Linux; MyBox)';
+
+test('parse', () => {
+	const ua = uap.parse('AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0');
+	assert.strictEqual(ua.os.name, 'ios');
+	assert.strictEqual(ua.os.version, '14.4');
+	assert.strictEqual(ua.client.name, 'cfnetwork');
+});
+
+test('addRule', () => {
+	const p = new uap.Parser();
+	try {
+		p.addRule('MyApp', uap.CLIENT, { priority: 2 });
+		p.addRule('mybox', uap.DEVICE, { priority: 3, source: uap.IN_COMMENT, deviceType: 4 });
+		const ua = p.parse(UA);
+		assert.strictEqual(ua.client.name, 'myapp');
+		assert.strictEqual(ua.device.name, 'mybox');
+		assert.strictEqual(ua.device_type, 4);
+
+		assert.throws(() => p.addRule('', uap.OS), uap.UapError);
+	} finally {
+		p.close();
+	}
+	assert.strictEqual(uap.parse(UA).client.name, undefined);
+});
+
+test('close', () => {
+	const p = new uap.Parser();
+	p.addRule('MyApp', uap.CLIENT);
+	p.close();
+	p.close();
+	assert.throws(() => p.parse(UA), /closed/);
+	assert.throws(() => p.addRule('other', uap.CLIENT), /closed/);
+});
@@ -0,0 +1,54 @@
+"""Tests of the ctypes binding against the built library (lib/build.sh):
+
+    python3 -m unittest discover lib/python
+"""
+
+import doctest
+import unittest
+
+import uap
+
+UA = "MyApp/2.1 (Linux; MyBox)"
+
+
+class TestUap(unittest.TestCase):
+    def test_parse(self):
+        ua = uap.parse("AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0")
+        self.assertEqual(ua["os"]["name"], "ios")
+        self.assertEqual(ua["os"]["version"], "14.4")
+        self.assertEqual(ua["client"]["name"], "cfnetwork")
+
+    def test_add_rule(self):
+        with uap.Parser() as p:
+            p.add_rule("MyApp", uap.CLIENT, priority=2)
+            p.add_rule("mybox", uap.DEVICE, priority=3, source=uap.IN_COMMENT, device_type=uap.SET_TOP)
+            ua = p.parse(UA)
+            self.assertEqual(ua["client"]["name"], "myapp")
+            self.assertEqual(ua["device"]["name"], "mybox")
+            self.assertEqual(ua["device_type"], uap.SET_TOP)
+
+            with self.assertRaises(uap.UapError) as e:
+                p.add_rule("", uap.OS)
+            self.assertEqual(e.exception.code, uap.UAP_ERR_RULE)
+
+        self.assertNotIn("name", uap.parse(UA)["client"])
+
+    def test_close(self):
+        p = uap.Parser()
+        p.add_rule("MyApp", uap.CLIENT)
+        p.close()
+        p.close()
+        with self.assertRaises(ValueError):
+            p.parse(UA)
+        with self.assertRaises(ValueError):
+            p.add_rule("other", uap.CLIENT)
+
+
+def load_tests(loader, tests, ignore):
+    # keep the examples of the module docstring in sync with the library
+    tests.addTests(doctest.DocTestSuite(uap))
+    return tests
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,147 @@
+"""ctypes binding of libuap, the C API of uaparser.
+
+Results are the JSON of the Go UserAgent decoded into dicts, so they match
+what the Go parser returns field by field. Empty fields such as conflicts,
+language or arch are left out, as in the Go JSON:
+
+    >>> import uap
+    >>> ua = uap.parse("AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0")
+    >>> ua["os"]
+    {'name': 'ios', 'version': '14.4', 'provenance': 'heuristic'}
+    >>> ua["client"]
+    {'name': 'cfnetwork', 'version': '1220.1', 'provenance': 'token'}
+    >>> ua["device_type"], ua["device_type_provenance"]
+    (1, 'default')
+
+The library is looked up in $UAP_LIBRARY, then next to this directory
+(lib/libuap.so as built by lib/build.sh).
+"""
+
+import ctypes
+import json
+import os
+import sys
+
+UAP_OK = 0
+UAP_ERR_NULL = -1
+UAP_ERR_HANDLE = -2
+UAP_ERR_RULE = -3
+
+# rule types
+OS = 1
+BROWSER = 2
+DEVICE = 3
+ENGINE = 4
+SKIP = 6
+CLIENT = 8
+
+# rule sources
+IN_PRODUCT = 0x01
+IN_COMMENT = 0x02
+IN_BOTH = 0x03
+
+# device types
+UNKNOWN_DEVICE = 0
+PHONE = 1
+TABLET = 2
+SMART_TV = 3
+SET_TOP = 4
+CONSOLE = 5
+DESKTOP = 6
+WEARABLE = 7
+XR = 8
+AUTOMOTIVE = 9
+SMART_SPEAKER = 10
+SMART_DISPLAY = 11
+IOT = 12
+
+
+class UapError(Exception):
+    def __init__(self, code):
+        super().__init__(_lib.uap_strerror(code).decode())
+        self.code = code
+
+
+def _load():
+    path = os.environ.get("UAP_LIBRARY")
+    if not path:
+        name = "libuap.dylib" if sys.platform == "darwin" else "libuap.so"
+        path = os.path.join(os.path.dirname(os.path.abspath(__file__)), "..", name)
+    lib = ctypes.CDLL(path)
+
+    parser = ctypes.c_size_t  # uintptr_t
+    lib.uap_parse_json.argtypes = [parser, ctypes.c_char_p, ctypes.POINTER(ctypes.c_void_p)]
+    lib.uap_parse_json.restype = ctypes.c_int
+    lib.uap_free.argtypes = [ctypes.c_void_p]
+    lib.uap_free.restype = None
+    lib.uap_parser_new.argtypes = []
+    lib.uap_parser_new.restype = parser
+    lib.uap_parser_free.argtypes = [parser]
+    lib.uap_parser_free.restype = None
+    lib.uap_parser_add_rule.argtypes = [parser, ctypes.c_char_p] + [ctypes.c_int] * 4
+    lib.uap_parser_add_rule.restype = ctypes.c_int
+    lib.uap_strerror.argtypes = [ctypes.c_int]
+    lib.uap_strerror.restype = ctypes.c_char_p
+    return lib
+
+
+_lib = _load()
+
+
+def _parse(handle, ua):
+    out = ctypes.c_void_p()
+    err = _lib.uap_parse_json(handle, ua.encode(), ctypes.byref(out))
+    if err != UAP_OK:
+        raise UapError(err)
+    try:
+        return json.loads(ctypes.string_at(out).decode())
+    finally:
+        _lib.uap_free(out)
+
+
+class Parser:
+    """A parser with custom rules on top of the built-in tables."""
+
+    def __init__(self):
+        self._handle = _lib.uap_parser_new()
+
+    def _check(self):
+        # handle 0 is the built-in parser, never fall back to it once closed
+        if self._handle is None:
+            raise ValueError("parser is closed")
+        return self._handle
+
+    def add_rule(self, token, type, priority=1, source=IN_PRODUCT, device_type=UNKNOWN_DEVICE):
+        err = _lib.uap_parser_add_rule(self._check(), token.encode(), type, priority, source, device_type)
+        if err != UAP_OK:
+            raise UapError(err)
+
+    def parse(self, ua):
+        return _parse(self._check(), ua)
+
+    def parse_batch(self, uas):
+        handle = self._check()
+        return [_parse(handle, ua) for ua in uas]
+
+    def close(self):
+        if getattr(self, "_handle", None) is not None:
+            _lib.uap_parser_free(self._handle)
+            self._handle = None
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.close()
+
+    def __del__(self):
+        self.close()
+
+
+def parse(ua):
+    """Parses ua with the built-in rules."""
+    return _parse(0, ua)
+
+
+def parse_batch(uas):
+    return [_parse(0, ua) for ua in uas]
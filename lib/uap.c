@@ -0,0 +1,46 @@
+#include <stdlib.h>
+#include <string.h>
+
+#include "uap.h"
+
+static void free_component(uap_component *c) {
+	free(c->name);
+	free(c->version);
+	free(c->build);
+	free(c->brand);
+	free(c->family);
+}
+
+void uap_result_free(uap_result *r) {
+	if (r == NULL) return;
+	free_component(&r->os);
+	free_component(&r->browser);
+	free_component(&r->device);
+	free_component(&r->engine);
+	free_component(&r->client);
+	free_component(&r->kernel);
+	free(r->language);
+	free(r->locale_language);
+	free(r->locale_script);
+	free(r->locale_region);
+	free(r->chromium);
+	free(r->arch);
+	free(r->os_arch);
+	free(r->tv_brand);
+	free(r->tv_platform);
+	memset(r, 0, sizeof(*r));
+}
+
+void uap_free(void *ptr) {
+	free(ptr);
+}
+
+const char *uap_strerror(int err) {
+	switch (err) {
+	case UAP_OK: return "ok";
+	case UAP_ERR_NULL: return "null argument";
+	case UAP_ERR_HANDLE: return "invalid parser handle";
+	case UAP_ERR_RULE: return "invalid rule";
+	}
+	return "unknown error";
+}
@@ -0,0 +1,132 @@
+/*
+ * uap.h - C API of the uaparser user agent parser.
+ *
+ * Build libuap.a (or libuap.so) with build.sh and link it together with
+ * -lpthread. All strings are UTF-8 and never NULL, the strings of a
+ * uap_result are owned by the result and released by uap_result_free.
+ */
+#ifndef UAP_H
+#define UAP_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* error codes */
+#define UAP_OK              0
+#define UAP_ERR_NULL       -1 /* a required argument is NULL */
+#define UAP_ERR_HANDLE     -2 /* unknown or freed parser handle */
+#define UAP_ERR_RULE       -3 /* invalid rule */
+
+/* device types */
+#define UAP_DEVICE_UNKNOWN       0
+#define UAP_DEVICE_PHONE         1
+#define UAP_DEVICE_TABLET        2
+#define UAP_DEVICE_SMARTTV       3
+#define UAP_DEVICE_SETTOP        4
+#define UAP_DEVICE_CONSOLE       5
+#define UAP_DEVICE_DESKTOP       6
+#define UAP_DEVICE_WEARABLE      7
+#define UAP_DEVICE_XR            8
+#define UAP_DEVICE_AUTOMOTIVE    9
+#define UAP_DEVICE_SMARTSPEAKER 10
+#define UAP_DEVICE_SMARTDISPLAY 11
+#define UAP_DEVICE_IOT          12
+
+/* rule types, fixed values: the Go constants are numbered explicitly */
+#define UAP_RULE_OS      1
+#define UAP_RULE_BROWSER 2
+#define UAP_RULE_DEVICE  3
+#define UAP_RULE_ENGINE  4
+#define UAP_RULE_SKIP    6
+#define UAP_RULE_CLIENT  8
+
+/* where a rule token is looked up */
+#define UAP_IN_PRODUCT 0x01 /* Name/version */
+#define UAP_IN_COMMENT 0x02 /* (...; token; ...) */
+#define UAP_IN_BOTH    0x03
+
+/*
+ * Opaque parser handle. 0 parses with the built-in rules, handles from
+ * uap_parser_new can carry custom rules. Handles are safe to share
+ * between threads.
+ */
+typedef uintptr_t uap_parser;
+
+typedef struct uap_component {
+	char *name;
+	char *version;
+	char *build;
+	char *brand;
+	char *family;
+} uap_component;
+
+/*
+ * The parse result. Provenance, conflicts and the HbbTV, Starboard and
+ * Roku details are only in the JSON of uap_parse_json.
+ */
+typedef struct uap_result {
+	int device_type;        /* UAP_DEVICE_* */
+	uap_component os;
+	uap_component browser;
+	uap_component device;
+	uap_component engine;
+	uap_component client;   /* native HTTP stack: cfnetwork, okhttp, ... */
+	uap_component kernel;   /* darwin kernel of CFNetwork apps */
+	char *language;         /* en-US */
+	char *locale_language;  /* en */
+	char *locale_script;    /* Hant */
+	char *locale_region;    /* US */
+	char *chromium;         /* Chrome version of Chromium based browsers */
+	char *arch;             /* process: x86, x86_64, arm, arm64, mips, ppc */
+	int bits;               /* of the process: 32, 64 or 0 */
+	char *os_arch;          /* x86_64 for a 32 bit browser on WOW64 */
+	char *tv_brand;
+	char *tv_platform;
+	int tv_year;
+	int mobile;
+	int webview;
+	int native_app;
+	int connected_tv;
+	int set_top_box;
+} uap_result;
+
+#ifndef UAP_NO_PROTOTYPES
+
+/* Parses ua into *out, which must be released with uap_result_free. */
+int uap_parse(uap_parser p, const char *ua, uap_result *out);
+
+/* Parses n user agents into out[0..n-1]. */
+int uap_parse_batch(uap_parser p, const char *const *uas, size_t n, uap_result *out);
+
+/* Parses ua into a JSON object, *json must be released with uap_free. */
+int uap_parse_json(uap_parser p, const char *ua, char **json);
+
+void uap_result_free(uap_result *r);
+void uap_free(void *ptr);
+
+uap_parser uap_parser_new(void);
+void uap_parser_free(uap_parser p);
+
+/*
+ * Adds a rule mapping the lower cased token to a component, e.g.
+ * uap_parser_add_rule(p, "myapp", UAP_RULE_CLIENT, 2, UAP_IN_PRODUCT, 0)
+ */
+int uap_parser_add_rule(uap_parser p, const char *token, int type, int priority, int source, int device_type);
+
+const char *uap_strerror(int err);
+
+/* deprecated: returns "<device type>;<device>;<os>;<browser>" */
+char *ParseUserAgent(char *ua);
+void FreeUserAgent(char *s);
+
+#endif
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* UAP_H */
@@ -0,0 +1,159 @@
+package uaparser
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is a BCP 47 language tag split into its subtags, in BCP 47 case:
+// en, Hant, US
+type Locale struct {
+	Language string `json:"language,omitempty"`
+	Script   string `json:"script,omitempty"`
+	Region   string `json:"region,omitempty"`
+}
+
+func (l Locale) String() string {
+	s := l.Language
+	if l.Script != "" {
+		s += "-" + l.Script
+	}
+	if l.Region != "" {
+		s += "-" + l.Region
+	}
+	return s
+}
+
+var primaryLanguages = make(map[string]bool)
+
+func init() {
+	for _, v := range languages {
+		primaryLanguages[strings.FieldsFunc(v, isTagSeparator)[0]] = true
+	}
+	for _, v := range moreLanguages {
+		primaryLanguages[v] = true
+	}
+}
+
+func isTagSeparator(c rune) bool {
+	return c == '-' || c == '_'
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseLocale accepts language[-script][-region] with "-" or "_", e.g.
+// es_us, zh-hant-tw, es-419. Only known primary languages are accepted, so
+// that device ids like sm-t are not taken for locales.
+func parseLocale(s string) (Locale, bool) {
+	var l Locale
+	tags := strings.FieldsFunc(strings.ToLower(strings.TrimSpace(s)), isTagSeparator)
+	if len(tags) == 0 || len(tags) > 3 || !primaryLanguages[tags[0]] {
+		return l, false
+	}
+	l.Language = tags[0]
+
+	for _, t := range tags[1:] {
+		switch {
+		case len(t) == 4 && isAlpha(t) && l.Script == "" && l.Region == "":
+			l.Script = strings.ToUpper(t[:1]) + t[1:]
+		case (len(t) == 2 && isAlpha(t) || len(t) == 3 && isNumeric(t)) && l.Region == "":
+			l.Region = strings.ToUpper(t)
+		default:
+			return Locale{}, false
+		}
+	}
+	return l, true
+}
+
+func (ua *UserAgent) useLocale(s string) bool {
+	l, ok := parseLocale(s)
+	if !ok {
+		return false
+	}
+	ua.Locale = l
+	ua.Language = l.String()
+	return true
+}
+
+// facebook apps keep the locale in the bracketed part:
+// [FBAN/FBIOS;FBDV/iPhone11,8;...;FBLC/en_US;FBOP/5]
+func facebookLocale(s string) string {
+	for _, x := range strings.Split(s, ";") {
+		if strings.HasPrefix(x, "fblc/") {
+			return x[len("fblc/"):]
+		}
+	}
+	return ""
+}
+
+// acceptLanguages returns the entries of an Accept-Language header, most
+// preferred first, q=0 marks a language as not acceptable
+func acceptLanguages(h string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+	var entries []entry
+	for _, x := range strings.Split(h, ",") {
+		parts := strings.Split(x, ";")
+		tag := strings.TrimSpace(parts[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, p := range parts[1:] {
+			if p = strings.TrimSpace(p); strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, entry{tag, q})
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	tags := make([]string, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
+
+// ParseHeaders parses the User-Agent header and falls back to
+// Accept-Language when the user agent carries no locale.
+func ParseHeaders(h http.Header) *UserAgent {
+	return parseHeaders(h, nil)
+}
+
+func parseHeaders(h http.Header, rules *Parser) *UserAgent {
+	ua := parseWith(h.Get("User-Agent"), rules)
+	if ua.Language == "" {
+		// the first entry that parses, a bad tag does not hide the rest
+		for _, tag := range acceptLanguages(h.Get("Accept-Language")) {
+			if ua.useLocale(tag) {
+				break
+			}
+		}
+	}
+	return ua
+}
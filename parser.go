@@ -7,6 +7,7 @@ import (
 
 type section struct {
 	name, version string
+	pos           int // order in the UA, see Criterion
 }
 
 type comment []*section
@@ -84,8 +85,8 @@ func parse(ua string) items {
 				inSkip -= 1
 				if inSkip == 0 {
 					result = append(result, &skip{name: ua[lastPos:i]})
+					lastPos = -1
 				}
-				lastPos = -1
 			} else if c == '[' {
 				inSkip += 1
 			}
@@ -99,47 +100,46 @@ func parse(ua string) items {
 	return result
 }
 
+// device types, the values are part of the C API (lib/uap.h)
 const (
-	UnknownDevice = iota
-	Phone
-	Tablet
-	SmartTV
-	SetTop
-	Console
-	Desktop
-	Wearable
+	UnknownDevice = 0
+	Phone         = 1
+	Tablet        = 2
+	SmartTV       = 3
+	SetTop        = 4
+	Console       = 5
+	Desktop       = 6
+	Wearable      = 7
+	XR            = 8
+	Automotive    = 9
+	SmartSpeaker  = 10
+	SmartDisplay  = 11
+	IoT           = 12
 )
 
 type Component struct {
-	Name, Version string
-	priority      int
-}
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Build   string `json:"build,omitempty"`
+	Brand   string `json:"brand,omitempty"`
+	Family  string `json:"family,omitempty"`
 
-func (c *Component) use(sec *section, reco *recognizer) bool {
-	if reco == nil {
-		c.Name = sec.name
-		c.Version = sec.version
-		return true
-	}
-
-	if reco.priority > c.priority {
-		c.Name = sec.name
-		c.Version = sec.version
-		c.priority = reco.priority
-		return true
-	}
+	Provenance Provenance `json:"provenance,omitempty"` // how Name was detected
 
-	return false
+	candidate candidate
 }
 
+// recognizer types, the values are part of the C API (lib/uap.h)
 const (
-	UNKNOWN = iota
-	OS
-	BROWSER
-	DEVICE
-	ENGINE
-	LANGUAGE
-	SKIP
+	UNKNOWN  = 0
+	OS       = 1
+	BROWSER  = 2
+	DEVICE   = 3
+	ENGINE   = 4
+	LANGUAGE = 5
+	SKIP     = 6
+	ARCH     = 7
+	CLIENT   = 8
 )
 
 const (
@@ -155,51 +155,61 @@ type recognizer struct {
 	rewrite    string
 	prefix     string
 	handler    func(ua *UserAgent, reco *recognizer, sec *section) bool
+	also       *recognizer // the browser or engine a client was reported as before Client existed
 }
 
 type UserAgent struct {
-	DeviceType                  int
-	OS, Browser, Device, Engine Component
-	Language                    string
+	DeviceType           int        `json:"device_type"`
+	DeviceTypeProvenance Provenance `json:"device_type_provenance,omitempty"`
+	OS                   Component  `json:"os"`
+	Browser              Component  `json:"browser"`
+	Device               Component  `json:"device"`
+	Engine               Component  `json:"engine"`
+	Client               Component  `json:"client"`             // native HTTP stack: cfnetwork, okhttp, dalvik, ...
+	Kernel               Component  `json:"kernel"`             // darwin kernel of CFNetwork apps, OS has the platform version
+	Language             string     `json:"language,omitempty"` // normalized locale tag, e.g. en-US
+	Locale               Locale     `json:"locale"`
+	Chromium             string     `json:"chromium,omitempty"` // Chrome version sent by Chromium based browsers
+	Arch                 string     `json:"arch,omitempty"`     // process architecture: x86, x86_64, arm, arm64, mips, ppc
+	Bits                 int        `json:"bits,omitempty"`     // of the process
+	OSArch               string     `json:"os_arch,omitempty"`  // x86_64 for a 32 bit browser on WOW64
+	TV                   TV         `json:"tv"`
+	HbbTV                *HbbTV     `json:"hbbtv,omitempty"`
+	Starboard            *Starboard `json:"starboard,omitempty"`
+	Roku                 *Roku      `json:"roku,omitempty"`
+	Conflicts            []Conflict `json:"conflicts,omitempty"` // candidates that lost, see Criterion
 
 	rv      string
 	tags    map[string]string
 	mobile  bool
 	webview bool
 	mozilla string
+	rules   *Parser
 }
 
 func (ua *UserAgent) ShortName() string {
 	return fmt.Sprintf("%d;%s;%s;%s", ua.DeviceType, ua.Device.Name, ua.OS.Name, ua.Browser.Name)
 }
 
-func (ua *UserAgent) try(sec *section, pos int, isProduct bool) bool {
+func (ua *UserAgent) try(sec *section, isProduct bool) bool {
 	var reco *recognizer
 	var ok bool
-	if isProduct {
-		reco, ok = productRecognizers[sec.name]
-	} else {
-		reco, ok = commentRecognizers[sec.name]
+	if ua.rules != nil {
+		reco, ok = ua.rules.lookup(sec.name, isProduct)
+	}
+	if !ok {
+		if isProduct {
+			reco, ok = productRecognizers[sec.name]
+		} else {
+			reco, ok = commentRecognizers[sec.name]
+		}
 	}
 	if ok {
 		if reco.handler == nil {
-			switch reco.typ {
-			case BROWSER:
-				ua.Browser.use(sec, reco)
-			case ENGINE:
-				ua.Engine.use(sec, reco)
-			case OS:
-				ua.OS.use(sec, reco)
-			case DEVICE:
-				if ua.Device.use(sec, reco) {
-					if reco.deviceType > 0 {
-						ua.DeviceType = reco.deviceType
-					}
-				}
-			case LANGUAGE:
-				ua.Language = sec.name
-			case SKIP:
+			if reco.rewrite != "" {
+				sec.name = reco.rewrite
 			}
+			ua.fill(sec, reco)
 			return true
 		} else {
 			if reco.handler(ua, reco, sec) {
@@ -211,10 +221,80 @@ func (ua *UserAgent) try(sec *section, pos int, isProduct bool) bool {
 	return false
 }
 
+// fill sets the field of a table recognizer
+func (ua *UserAgent) fill(sec *section, reco *recognizer) {
+	switch reco.typ {
+	case BROWSER:
+		if (sec.name == "chrome" || sec.name == "chromium") && ua.Chromium == "" {
+			ua.Chromium = sec.version
+		}
+		ua.use("browser", &ua.Browser, sec, reco)
+	case ENGINE:
+		ua.use("engine", &ua.Engine, sec, reco)
+	case OS:
+		ua.use("os", &ua.OS, sec, reco)
+	case DEVICE:
+		if ua.use("device", &ua.Device, sec, reco) {
+			if reco.deviceType > 0 {
+				ua.setType(reco.deviceType, provenanceOf(reco))
+			}
+		}
+	case LANGUAGE:
+		ua.useLocale(sec.name)
+	case ARCH:
+		ua.setArch(sec.name)
+	case CLIENT:
+		ua.use("client", &ua.Client, sec, reco)
+		ua.tag(sec)
+		if reco.also != nil {
+			ua.fill(sec, reco.also)
+		}
+	case SKIP:
+	}
+}
+
+// tryPrefix runs the handler of the longest matching prefix recognizer
+// that accepts sec
+func tryPrefix(ua *UserAgent, recognizers *prefixTrie, sec *section) bool {
+	return recognizers.try(ua, sec, sec.name)
+}
+
+// tag records sections listed in knownTags
+func (ua *UserAgent) tag(sec *section) bool {
+	t, ok := knownTags[sec.name]
+	if !ok {
+		return false
+	}
+	if t == "" {
+		t = sec.name
+	}
+	ua.tags[t] = sec.version
+	return true
+}
+
+// Parse parses s with the built-in rules
 func Parse(s string) *UserAgent {
+	return parseWith(s, nil)
+}
+
+func parseWith(s string, rules *Parser) *UserAgent {
 	s = strings.ToLower(s)
+	hbbtv := parseHbbTV(s)
 	s = strings.Replace(s, "+", " ", -1)
 	items := parse(s)
+	pos := 0
+	for _, item := range items {
+		switch x := item.(type) {
+		case *section:
+			x.pos = pos
+			pos++
+		case comment:
+			for _, sec := range x {
+				sec.pos = pos
+				pos++
+			}
+		}
+	}
 
 	lastPos := 0
 	mergeItems := func(end int) {
@@ -239,23 +319,6 @@ func Parse(s string) *UserAgent {
 		lastPos = -1
 	}
 
-	tryPrefix := func(ua *UserAgent, recognizers []*recognizer, sec *section) bool {
-		used := false
-		for _, reco := range recognizers {
-			if reco.prefix == "" || reco.handler == nil {
-				continue
-			}
-
-			if strings.HasPrefix(sec.name, reco.prefix) {
-				if reco.handler(ua, reco, sec) {
-					used = true
-					break
-				}
-			}
-		}
-		return used
-	}
-
 	var firstTag string
 	if len(items) > 0 {
 		if sec, ok := items[0].(*section); ok {
@@ -292,9 +355,8 @@ func Parse(s string) *UserAgent {
 		mergeItems(len(items) - 1)
 	}
 
-	ua := &UserAgent{tags: make(map[string]string)}
+	ua := &UserAgent{tags: make(map[string]string), HbbTV: hbbtv, rules: rules}
 	if len(items) == 0 {
-		fmt.Printf("no items: %#v\n", items)
 		return ua
 	}
 
@@ -329,32 +391,66 @@ func Parse(s string) *UserAgent {
 	}
 
 	if len(products) == 0 {
-		fmt.Printf("no products: %#v: %s\n", items, s)
 		return ua
 	}
 
+	for _, item := range items {
+		if sk, ok := item.(*skip); ok {
+			ua.useLocale(facebookLocale(sk.name))
+		}
+	}
+
 	var xProducts, xComments []*section
 	if sec := products[0].section; sec != nil {
 		if sec.name == "mozilla" {
 			ua.mozilla = sec.version
 		} else {
-			if !ua.try(sec, 0, true) {
-				tryPrefix(ua, productPrefixRecognizers, sec)
+			if !ua.try(sec, true) && !tryPrefix(ua, productPrefixRecognizers, sec) {
+				ua.tag(sec)
 			}
 		}
 	}
 
-	//only use the first non-empty comment
+	//only use the first non-empty comment, the HbbTV one is parsed by
+	//parseHbbTV and the OS follows in a later section:
+	//HbbTV/1.2.1 (+DRM;Samsung;SmartTV2015;...) Mozilla/5.0 (SMART-TV; Linux; Tizen 2.3)
 	var comments comment
 	for i := 0; i < len(products); i += 1 {
+		if sec := products[i].section; hbbtv != nil && sec != nil && sec.name == "hbbtv" {
+			continue
+		}
 		comments = products[i].comment
 		if comments != nil {
 			break
 		}
 	}
 
+	// a bare language is only a locale after the U (I, N) security token:
+	// (Linux; U; Android 4.0.3; en; HTC Sensation), with a region it can be
+	// anywhere: (X11; FreeBSD; Viera; de-DE). A Build/ id is Android before
+	// the OS is known: (Linux; Pixel 3 Build/PQ3A.190801.002; wv)
+	secure, built := false, false
+	for _, sec := range comments {
+		if sec.name == "u" || sec.name == "i" || sec.name == "n" {
+			secure = true
+		}
+		if sec.version != "" && (sec.name == "build" || strings.HasSuffix(sec.name, " build")) {
+			built = true
+		}
+	}
+
 	for _, sec := range comments {
-		if ua.try(sec, 0, false) {
+		// "SM-G900F Build/MMB29M": keep the build id, the rest is the device
+		if sec.version != "" && (sec.name == "build" || strings.HasSuffix(sec.name, " build")) {
+			ua.OS.Build = sec.version
+			sec.name = strings.TrimSuffix(sec.name, " build")
+			sec.version = ""
+			if sec.name == "build" {
+				continue
+			}
+		}
+
+		if ua.try(sec, false) {
 			continue
 		}
 
@@ -363,16 +459,12 @@ func Parse(s string) *UserAgent {
 			continue
 		}
 
-		if sec.name == "wv" && ua.OS.Name == "android" {
+		if sec.name == "wv" && (ua.OS.Name == "android" || built) {
 			ua.webview = true
 			continue
 		}
 
-		if t, ok := knownTags[sec.name]; ok {
-			if t == "" {
-				t = sec.name
-			}
-			ua.tags[t] = sec.version
+		if ua.tag(sec) {
 			continue
 		}
 
@@ -380,24 +472,31 @@ func Parse(s string) *UserAgent {
 			continue
 		}
 
+		if ua.Language == "" && sec.version == "" && (secure || strings.ContainsAny(sec.name, "-_")) && ua.useLocale(sec.name) {
+			continue
+		}
+
 		xComments = append(xComments, sec)
 	}
 
 	for i := 1; i < len(products); i += 1 {
 		sec := products[i].section
-		if ua.try(sec, i, true) {
+		if ua.try(sec, true) {
 			continue
 		}
 
 		if sec.name == "mobile" {
 			ua.mobile = true
+		} else if strings.HasPrefix(sec.name, "mobile ") {
+			// Mobile DuckDuckGo/5 is merged into one section
+			ua.mobile = true
+			sec.name = strings.TrimPrefix(sec.name, "mobile ")
+			if ua.try(sec, true) {
+				continue
+			}
 		}
 
-		if t, ok := knownTags[sec.name]; ok {
-			if t == "" {
-				t = sec.name
-			}
-			ua.tags[t] = sec.version
+		if ua.tag(sec) {
 			continue
 		}
 
@@ -408,11 +507,38 @@ func Parse(s string) *UserAgent {
 		xProducts = append(xProducts, sec)
 	}
 
+	// android without "Android x.y", e.g. Dalvik/2.1.0 (Linux; U; SM-G900F Build/MMB29M)
+	if ua.OS.Build != "" && (ua.OS.Name == "" || ua.OS.Name == "linux" || ua.OS.Name == "android") {
+		if v, ok := androidBuildVersion(ua.OS.Build); ok {
+			if ua.OS.Name != "android" {
+				ua.OS.set("android", Heuristic)
+				ua.OS.Version = ""
+			}
+			if ua.OS.Version == "" {
+				ua.OS.Version = v
+			}
+		}
+	}
+
+	if ua.HbbTV != nil {
+		if ua.Device.Brand == "" {
+			ua.Device.Brand = ua.HbbTV.Vendor
+		}
+		if ua.Device.Name == "" {
+			ua.Device.set(ua.HbbTV.Model, ExplicitToken)
+		}
+	}
+
 	// extra rules
 	switch ua.OS.Name {
-	case "linux":
+	case "linux", "steamos":
 		if strings.HasPrefix(ua.OS.Version, "smarttv") {
-			ua.DeviceType = SmartTV
+			ua.setType(SmartTV, PrefixRule)
+		}
+		// Big Picture on Linux is most likely a Deck, on Windows it is a desktop
+		if _, ok := ua.tags["gamepadui"]; ok && ua.Device.Name == "" {
+			ua.Device.set("steamdeck", Heuristic)
+			ua.setType(Console, Heuristic)
 		}
 	case "android":
 		// android: last comment is device id
@@ -420,113 +546,136 @@ func Parse(s string) *UserAgent {
 			sec := xComments[len(xComments)-1]
 			xComments = xComments[:len(xComments)-1]
 
-			sec.name = strings.TrimSuffix(sec.name, " build")
-			ua.Device.use(sec, nil)
+			ua.use("device", &ua.Device, sec, nil)
 		}
 
 		if _, ok := ua.tags["ctv"]; ok {
-			ua.DeviceType = SmartTV
+			ua.setType(SmartTV, ExplicitToken)
+		}
+
+		if _, ok := ua.tags["automotive"]; ok {
+			ua.setType(Automotive, ExplicitToken)
 		}
 
-		if ua.Engine.Name == "exoplayerlib" && ua.Browser.Name == "" {
-			ua.Browser.Name = "exoplayerapp"
+		if ua.Client.Name == "exoplayerlib" && ua.Browser.Name == "" {
+			ua.Browser.set("exoplayerapp", Heuristic)
 		}
 
 		//popular devices
 		if strings.HasPrefix(ua.Device.Name, "aft") { // Amazon Fire TV
-			ua.DeviceType = SmartTV
+			ua.setType(SmartTV, PrefixRule)
 		} else if strings.HasPrefix(ua.Device.Name, "kf") { // Amazon Kindle Fire
-			ua.DeviceType = Tablet
+			ua.setType(Tablet, PrefixRule)
+		} else if strings.HasPrefix(ua.Device.Name, "aeo") { // Amazon Echo Show
+			ua.setType(SmartDisplay, PrefixRule)
+			ua.Device.Brand = "amazon"
+		} else if isWearOSModel(ua.Device.Name) {
+			ua.setType(Wearable, PrefixRule)
+			ua.OS.set("wearos", PrefixRule)
+			ua.OS.Version = wearOSVersions[major(ua.OS.Version)]
 		} else if strings.HasSuffix(ua.Device.Name, "tv") {
-			ua.DeviceType = SmartTV
+			ua.setType(SmartTV, Heuristic)
 		}
 
 	case "windows_nt":
 		if ua.Browser.Name == "" && ua.rv != "" {
-			ua.Browser.Name = "msie"
+			ua.Browser.set("msie", Heuristic)
 			ua.Browser.Version = ua.rv
 		}
 		//TODO: normalize windows
 
+	case "tizen":
+		// Mozilla/5.0 (Linux; Tizen 2.3; SAMSUNG SM-R720) AppleWebKit/537.3 (KHTML, like Gecko)Version/2.3 Mobile Safari/537.3
+		for _, sec := range xComments {
+			if name := strings.TrimPrefix(sec.name, "samsung "); strings.HasPrefix(name, "sm-r") {
+				ua.Device.set(name, PrefixRule)
+				ua.setType(Wearable, PrefixRule)
+			}
+		}
+
 	case "tvos":
 		if ua.Device.Name == "" {
-			ua.Device.Name = "appletv"
+			ua.Device.set("appletv", Heuristic)
 		}
-		ua.DeviceType = SmartTV
+		ua.setType(SmartTV, ExplicitToken)
 
 	case "rokuos": //Cobalt
-		ua.DeviceType = SmartTV
-		ua.Device.Name = "roku"
-		ua.OS.Name = ""
+		ua.setType(SmartTV, ExplicitToken)
+		ua.Device.set("roku", Heuristic)
 	}
 
 	//tagging
 	if _, ok := ua.tags["tablet"]; ok {
-		ua.DeviceType = Tablet
+		ua.setType(Tablet, ExplicitToken)
 	} else if _, ok := ua.tags["mobile"]; ok {
 		ua.mobile = true
 	}
 
-	// iOS apps
-	if _, ok := ua.tags["cfnetwork"]; ok && ua.OS.Name == "darwin" && ua.Device.Name == "" {
-		if strings.HasPrefix(firstTag, "appletv") || strings.HasSuffix(firstTag, "tvos") {
-			ua.OS.Name = "tvos"
-			ua.Device.Name = "appletv"
-			ua.DeviceType = SmartTV
+	// iOS apps, the app name only hints at the device: iPad/1.0 CFNetwork/...
+	if ua.Client.Name == "cfnetwork" && ua.OS.Name == "darwin" && ua.Device.Name == "" {
+		ua.Kernel = ua.OS
+		if strings.HasPrefix(firstTag, "mac") || ua.Arch != "" { // Darwin/20.3.0 (x86_64)
+			ua.OS.set("macosx", Heuristic)
+			ua.setType(Desktop, Heuristic)
+		} else if strings.HasPrefix(firstTag, "appletv") || strings.HasSuffix(firstTag, "tvos") {
+			ua.OS.set("tvos", Heuristic)
+			ua.Device.set("appletv", Heuristic)
+			ua.setType(SmartTV, Heuristic)
+		} else if strings.HasPrefix(firstTag, "watch") {
+			ua.OS.set("watchos", Heuristic)
+			ua.Device.set("applewatch", Heuristic)
+			ua.setType(Wearable, Heuristic)
+		} else if strings.HasPrefix(firstTag, "realitydevice") {
+			ua.OS.set("visionos", Heuristic)
+			ua.Device.set("visionpro", Heuristic)
+			ua.setType(XR, Heuristic)
 		} else {
 			ua.mobile = true
-			ua.OS.Name = "ios"
 			if strings.HasPrefix(firstTag, "ipad") {
-				ua.DeviceType = Tablet
-				ua.Device.Name = "ipad"
+				ua.OS.set("ios", Heuristic)
+				ua.Device.set("ipad", Heuristic)
+				ua.setType(Tablet, Heuristic)
+			} else if strings.HasPrefix(firstTag, "iphone") {
+				ua.OS.set("ios", Heuristic)
+				ua.Device.set("iphone", Heuristic)
+				ua.setType(Phone, Heuristic)
 			} else {
-				ua.DeviceType = Phone
-				if strings.HasPrefix(firstTag, "iphone") {
-					ua.Device.Name = "iphone"
-				}
+				ua.OS.set("ios", Heuristic)
+				ua.setType(Phone, Default)
 			}
 		}
+		ua.OS.Version = appleVersion(ua.OS.Name, ua.Kernel.Version, ua.Client.Version)
 	}
 
 	// second phase after tagging
 	if ua.Engine.Name == "cobalt" {
-		for _, p := range xProducts {
-			if strings.HasPrefix(p.name, "_") {
-				names := strings.Split(p.name[1:], "_")
-				switch names[0] {
-				case "ott":
-					fallthrough
-				case "atv":
-					fallthrough
-				case "tv":
-					ua.DeviceType = SmartTV
-				case "stb":
-					ua.DeviceType = SetTop
-				case "game":
-					ua.DeviceType = Console
-				}
-
-				if ua.Device.Name == "" {
-					if len(names) >= 2 && names[1] != "" {
-						ua.Device.Name = names[1]
-					} else {
-						ua.Device.Name = names[0]
-					}
-				}
-
-				if ua.OS.Name == "darwin" && ua.Device.Name == "ott" {
-					ua.Device.Name = "appletv"
+		for _, p := range products[1:] {
+			if sb := parseStarboard(p); sb != nil {
+				ua.useStarboard(sb)
+				break
+			}
+		}
+		if ua.Starboard != nil {
+			for _, p := range xProducts {
+				if p.name == "starboard" || strings.HasSuffix(p.name, " starboard") { // gles starboard/11
+					ua.Starboard.Version = strings.TrimSuffix(p.version, ",")
 				}
 			}
 		}
 	}
 
+	if strings.HasPrefix(ua.Device.Name, "roku") || ua.OS.Name == "rokuos" {
+		ua.useRoku(products)
+	}
+
+	tv := ua.detectTV(products)
+
 	if ua.OS.Name == "android" {
 		if ua.DeviceType == UnknownDevice {
-			ua.DeviceType = Phone
+			ua.setType(Phone, Default)
 		}
 	} else if ua.Browser.Name == "safari" && ua.mobile {
-		ua.Browser.Name = "mobile safari"
+		ua.Browser.set("mobile safari", ua.Browser.Provenance)
 	}
 
 	if ua.DeviceType == UnknownDevice {
@@ -534,35 +683,39 @@ func Parse(s string) *UserAgent {
 		case "hbbtv":
 			fallthrough
 		case "youviewhtml":
-			ua.DeviceType = SmartTV
+			ua.setType(SmartTV, Heuristic)
 
 		//LG Browser/8.00.00 (webOS.TV-2017), _TV_M2R/05.80.02 (LG, 43LJ5500-SA, wireless),gzip(gfe)
 		case "lg browser":
 			for _, p := range xProducts {
 				if strings.HasPrefix(p.name, "_tv_") || strings.HasPrefix(p.name, "lg netcast.tv") || strings.HasPrefix(p.name, "webos.tv") || strings.HasPrefix(p.name, "lg simplesmart.tv") || strings.HasPrefix(p.name, "lg netcast.media") {
-					ua.DeviceType = SmartTV
+					ua.setType(SmartTV, Heuristic)
 				}
 			}
 
 		case "opr": //opera
-			if _, ok := ua.tags["omi"]; ok && ua.OS.Name == "linux" && (strings.HasPrefix(ua.OS.Version, "armv") || ua.OS.Version == "mips") {
-				ua.DeviceType = SmartTV
+			if _, ok := ua.tags["omi"]; ok && ua.OS.Name == "linux" && (ua.Arch == "arm" || ua.Arch == "mips") {
+				ua.setType(SmartTV, Heuristic)
 			}
 		}
 
 		if ua.OS.Name == "ios" {
-			ua.DeviceType = Phone
+			ua.setType(Phone, Default)
 		} else if ua.OS.Name == "tizen" {
-			ua.DeviceType = SmartTV
+			ua.setType(SmartTV, Heuristic)
+		} else if ua.OS.Name == "watchos" || ua.OS.Name == "wearos" {
+			ua.setType(Wearable, ua.OS.Provenance)
+		} else if ua.OS.Name == "visionos" {
+			ua.setType(XR, ua.OS.Provenance)
 		}
 	}
 
 	if ua.DeviceType == UnknownDevice && !ua.mobile && ua.Browser.Name != "" {
 		if ua.OS.Name == "windows_nt" || ua.OS.Name == "macosx" || ua.OS.Name == "chromeos" {
-			ua.DeviceType = Desktop
+			ua.setType(Desktop, Heuristic)
 		} else if ua.OS.Name == "linux" {
-			if strings.HasPrefix(ua.OS.Version, "x86_64") || ua.OS.Version == "i686" {
-				ua.DeviceType = Desktop
+			if ua.Arch == "x86_64" || ua.Arch == "x86" {
+				ua.setType(Desktop, Heuristic)
 			}
 		}
 
@@ -570,57 +723,89 @@ func Parse(s string) *UserAgent {
 
 	switch ua.Device.Name {
 	case "roku 3":
-		ua.Device.Name = "roku"
+		ua.Device.set("roku", ua.Device.Provenance)
+	case "playstation 5":
+		ua.Device.set("ps5", ua.Device.Provenance)
 	case "playstation 4":
-		ua.Device.Name = "ps4"
+		ua.Device.set("ps4", ua.Device.Provenance)
 	case "playstation 3":
-		ua.Device.Name = "ps3"
+		ua.Device.set("ps3", ua.Device.Provenance)
 	case "crkey":
-		ua.Device.Name = "chromecast"
+		ua.Device.set("chromecast", ua.Device.Provenance)
 	case "nintendo wiiu":
-		ua.Device.Name = "wiiu"
+		ua.Device.set("wiiu", ua.Device.Provenance)
 	case "nintendo switch":
-		ua.Device.Name = "switch"
-	case "xboxone":
-		ua.Device.Name = "xbox"
+		ua.Device.set("switch", ua.Device.Provenance)
+	case "nintendo 3ds":
+		ua.Device.set("3ds", ua.Device.Provenance)
+	case "new nintendo 3ds", "new nintendo 3ds like iphone":
+		ua.Device.set("new3ds", ua.Device.Provenance)
 	case "xbox one":
-		ua.Device.Name = "xbox"
-	case "xbox360":
-		ua.Device.Name = "xbox"
+		ua.Device.set("xboxone", ua.Device.Provenance)
+	case "xbox_one_ed":
+		ua.Device.set("xboxone", ua.Device.Provenance)
+	case "xbox series x":
+		ua.Device.set("xboxseriesx", ua.Device.Provenance)
+	case "xbox series s":
+		ua.Device.set("xboxseriess", ua.Device.Provenance)
+	case "quest", "quest 2", "quest 3", "quest 3s", "quest pro":
+		ua.Device.set(strings.Replace(ua.Device.Name, " ", "", -1), ua.Device.Provenance)
+		ua.OS.set("horizonos", ua.Device.Provenance)
+		ua.OS.Version = ""
+	case "pacific":
+		ua.Device.set("oculusgo", ua.Device.Provenance)
+	case "alexamediaplayer":
+		ua.Device.set("echo", ua.Device.Provenance)
+	case "apple watch":
+		ua.Device.set("applewatch", ua.Device.Provenance)
+	case "apple vision", "apple vision pro":
+		ua.Device.set("visionpro", ua.Device.Provenance)
+	case "steam deck":
+		ua.Device.set("steamdeck", ua.Device.Provenance)
+		if ua.OS.Name == "" {
+			ua.OS.set("steamos", Heuristic)
+		}
 	}
 
 	if ua.DeviceType == UnknownDevice && firstTag != "" {
 		name := firstTag
 		if x, ok := knownProducts[name]; ok {
-			ua.DeviceType = x.DeviceType
+			ua.setType(x.DeviceType, ExplicitToken)
 			if ua.Device.Name == "" {
-				ua.Device.Name = x.Device.Name
+				ua.Device.set(x.Device.Name, ExplicitToken)
 			}
 		} else {
+			// a guess from the app name, AndroidApp/3.1 (Linux; U)
 			if strings.HasPrefix(name, "appletv") {
-				ua.Device.Name = "appletv"
-				ua.DeviceType = SmartTV
+				ua.Device.set("appletv", Heuristic)
+				ua.setType(SmartTV, Heuristic)
 			} else if strings.HasPrefix(name, "iphone") {
-				ua.OS.Name = "ios"
-				ua.Device.Name = "iphone"
-				ua.DeviceType = Phone
+				ua.OS.set("ios", Heuristic)
+				ua.Device.set("iphone", Heuristic)
+				ua.setType(Phone, Heuristic)
 			} else if strings.HasPrefix(name, "ipod") {
-				ua.OS.Name = "ios"
-				ua.Device.Name = "iphone"
-				ua.DeviceType = Phone
+				ua.OS.set("ios", Heuristic)
+				ua.Device.set("iphone", Heuristic)
+				ua.setType(Phone, Heuristic)
 			} else if strings.HasPrefix(name, "ipad") {
-				ua.OS.Name = "ios"
-				ua.Device.Name = "ipad"
-				ua.DeviceType = Tablet
+				ua.OS.set("ios", Heuristic)
+				ua.Device.set("ipad", Heuristic)
+				ua.setType(Tablet, Heuristic)
 			} else if strings.HasPrefix(name, "androidtv") {
-				ua.Device.Name = "androidtv"
-				ua.DeviceType = SmartTV
+				ua.Device.set("androidtv", Heuristic)
+				ua.setType(SmartTV, Heuristic)
 			} else if strings.HasPrefix(name, "android") {
-				ua.OS.Name = "android"
-				ua.DeviceType = Phone
+				ua.OS.set("android", Heuristic)
+				ua.setType(Phone, Heuristic)
 			}
 		}
 	}
 
+	if ua.DeviceType == SmartTV {
+		ua.TV = tv
+	}
+
+	ua.deriveEngine()
+
 	return ua
 }
@@ -1,7 +1,11 @@
 package uaparser
 
 import (
-	//	"fmt"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -16,13 +20,55 @@ func TestParse(t *testing.T) {
 			"6;;linux;chrome"},
 		tcase{"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko", "6;;windows_nt;msie"},
 		//Roku
-		tcase{"Roku/DVP-9.0 (289.00E04144A)", "3;roku;;"},
+		tcase{"Roku/DVP-9.0 (289.00E04144A)", "3;roku;rokuos;"},
 		tcase{"com.google.android.youtube/14.08.55(Linux; U; Android 6.0; es_US; M4 SS4457 Build/MRA58K) gzip,gzip(gfe)", "1;m4 ss4457;android;"},
 		tcase{"com.google.ios.youtube/14.07.7 (iPhone11,8; U; CPU iOS 12_1_4 like Mac OS X; en_US)", "1;iphone;ios;"},
 
-		//"Mozilla/5.0 (RokuOS) Cobalt/9.174384-gold (unlike Gecko) Starboard/4, Roku_OTT_MC2/9.0 (Roku, 3900X, Wireless),gzip(gfe)"
+		tcase{"Mozilla/5.0 (RokuOS) Cobalt/9.174384-gold (unlike Gecko) Starboard/4, Roku_OTT_MC2/9.0 (Roku, 3900X, Wireless),gzip(gfe)", "3;roku;rokuos;"},
+		tcase{"Mozilla/5.0 (X11; Linux x86_64) Cobalt/19.lts.4.196747-gold (unlike Gecko) v8/6.5.254.43 gles Starboard/10, _TV_M2R/05.80.02 (LG, 43LJ5500-SA, wireless),gzip(gfe)", "3;43lj5500-sa;linux;"},
 
-		tcase{"Roku/DVP-9.0 (519.00E04142A),gzip(gfe)", ""},
+		//consoles
+		tcase{"Mozilla/5.0 (PlayStation; PlayStation 5/2.26) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.0 Safari/605.1.15", "5;ps5;;safari"},
+		tcase{"Mozilla/5.0 (PlayStation 4 5.55) AppleWebKit/601.2 (KHTML, like Gecko)", "5;ps4;;"},
+		tcase{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; Xbox; Xbox Series X) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/48.0.2564.82 Safari/537.36 Edge/20.02", "5;xboxseriesx;windows_nt;edge"},
+		tcase{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; XBOX_ONE_ED) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/51.0.2704.79 Safari/537.36 Edge/14.14393", "5;xboxone;windows_nt;edge"},
+		tcase{"Mozilla/5.0 (compatible; MSIE 10.0; Windows NT 6.2; Trident/6.0; Xbox)", "5;xbox;windows_nt;msie"},
+		tcase{"Mozilla/5.0 (Nintendo Switch; WifiWebAuthApplet) AppleWebKit/606.4 (KHTML, like Gecko) NF/6.0.1.15.4 NintendoBrowser/5.1.0.20393", "5;switch;;nintendobrowser"},
+		tcase{"Mozilla/5.0 (New Nintendo 3DS like iPhone) AppleWebKit/536.30 (KHTML, like Gecko) NX/3.0.0.5.15 Mobile NintendoBrowser/1.3.10126.EU", "5;new3ds;;nintendobrowser"},
+		tcase{"Mozilla/5.0 (X11; Linux x86_64; Valve Steam GamepadUI/1665786434; ) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/104.0.5112.81 Safari/537.36", "5;steamdeck;linux;chrome"},
+		tcase{"Mozilla/5.0 (X11; Linux x86_64; Steam Deck) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/104.0.5112.81 Safari/537.36", "5;steamdeck;linux;chrome"},
+		tcase{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; Valve Steam GamepadUI/1700000000) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.6478.183 Safari/537.36", "6;;windows_nt;chrome"},
+		tcase{"Mozilla/5.0 (X11; Linux x86_64; Quest 2) AppleWebKit/537.36 (KHTML, like Gecko) OculusBrowser/15.0.0.0.22.280317669 SamsungBrowser/4.0 Chrome/89.0.4389.90 VR Safari/537.36", "8;quest2;horizonos;oculusbrowser"},
+
+		//wearables and xr
+		tcase{"MyApp/1.0 (Apple Watch; watchOS 7.4; Scale/2.00)", "7;applewatch;watchos;"},
+		tcase{"Mozilla/5.0 (Linux; Android 11; Pixel Watch Build/RWD9.220429.053; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/104.0.5112.69 Mobile Safari/537.36", "7;pixel watch;wearos;chrome"},
+		tcase{"Mozilla/5.0 (Linux; Android 12; Nightwatch X5 Build/SP1A.210812.016) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/110.0.5481.153 Mobile Safari/537.36", "1;nightwatch x5;android;chrome"},
+		tcase{"Mozilla/5.0 (Linux; Tizen 2.3; SAMSUNG SM-R720) AppleWebKit/537.3 (KHTML, like Gecko)Version/2.3 Mobile Safari/537.3", "7;sm-r720;tizen;mobile safari"},
+		tcase{"Mozilla/5.0 (Linux; Android 10; Pico Neo 3 Link) AppleWebKit/537.36 (KHTML, like Gecko) PicoBrowser/3.3.22 Chrome/105.0.5195.68 VR Safari/537.36", "8;pico;android;picobrowser"},
+		tcase{"Mozilla/5.0 (Apple Vision Pro; CPU visionOS 1_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148", "8;visionpro;visionos;"},
+
+		//cars, speakers, displays and iot
+		tcase{"Mozilla/5.0 (X11; GNU/Linux) AppleWebKit/537.36 (KHTML, like Gecko) Chromium/79.0.3945.130 Chrome/79.0.3945.130 Safari/537.36 Tesla/2020.16.2.1-e99c70fff409", "9;tesla;;chrome"},
+		tcase{"Mozilla/5.0 (Linux; Android 10; Automotive; Polestar 2 Build/QP1A.190711.020) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/88.0.4324.181 Safari/537.36", "9;polestar 2;android;chrome"},
+		tcase{"Mozilla/5.0 (Linux; Android 12; AAOS; Polestar 2 Build/SQ3A.220705.003) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/112.0.5615.135 Safari/537.36", "9;polestar 2;android;chrome"},
+		tcase{"Mozilla/5.0 (Linux; Android 10; Polestar 2 Build/QP1A.190711.020) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/88.0.4324.181 Safari/537.36", "1;polestar 2;android;chrome"},
+		tcase{"Mozilla/5.0 (Linux; Android 7.1.2; AEOKN) AppleWebKit/537.36 (KHTML, like Gecko) Silk/81.2.16 like Chrome/81.0.4044.138 Safari/537.36", "11;aeokn;android;silk"},
+		tcase{"Mozilla/5.0 (Fuchsia) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/114.0.0.0 Safari/537.36 CrKey/1.56.500000 DeviceType/SmartDisplay", "11;nesthub;fuchsia;chrome"},
+		tcase{"Mozilla/5.0 (X11; Linux armv7l) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/78.0.3904.108 Safari/537.36 CrKey/1.44.191160 DeviceType/Chromecast", "3;chromecast;linux;chrome"},
+		tcase{"AlexaMediaPlayer/2.1.4676.0 (Linux;Android 5.1.1) ExoPlayerLib/1.5.9", "10;echo;android;exoplayerapp"},
+		tcase{"ESP8266HTTPClient", "12;esp8266httpclient;;"},
+
+		//chromium forks
+		tcase{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91", "6;;windows_nt;edge"},
+		tcase{"Mozilla/5.0 (Linux; Android 13; SM-S908B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Mobile Safari/537.36", "1;sm-s908b;android;samsungbrowser"},
+		tcase{"Mozilla/5.0 (Linux; Android 13) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/120.0.6099.144 Mobile DuckDuckGo/5 Safari/537.36", "1;;android;duckduckgo"},
+
+		tcase{"Dalvik/2.1.0 (Linux; U; SM-G900F Build/MMB29M)", "1;sm-g900f;android;dalvik"},
+		tcase{"Mozilla/5.0 (Linux; Pixel 3 Build/PQ3A.190801.002; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/76.0.3809.89 Mobile Safari/537.36", "1;pixel 3;android;chrome"},
+		tcase{"HbbTV/1.2.1 (+DRM;Samsung;SmartTV2015;T-HKM6DEUC-1490.3;;) Mozilla/5.0 (SMART-TV; Linux; Tizen 2.3) AppleWebKit/538.1 (KHTML, like Gecko) SamsungBrowser/1.0 TV Safari/538.1", "3;smarttv2015;tizen;hbbtv"},
+		tcase{"Roku/DVP-9.0 (519.00E04142A),gzip(gfe)", "3;roku;rokuos;"},
+		tcase{"Roku4640X/DVP-7.70 (297.70E04154A)", "3;roku;rokuos;"},
 		//Android 8.0.0 (samsung; SM-A600FN; Sky Go Android PR17.3.3-1100)
 	}
 
@@ -33,3 +79,529 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestAndroidBuild(t *testing.T) {
+	cases := []struct {
+		in, build, version string
+	}{
+		{"com.google.android.youtube/14.08.55(Linux; U; Android 6.0; es_US; M4 SS4457 Build/MRA58K) gzip,gzip(gfe)", "mra58k", "6.0"},
+		{"Dalvik/2.1.0 (Linux; U; SM-G900F Build/MMB29M)", "mmb29m", "6.0.1"},
+		{"Mozilla/5.0 (Linux; Pixel 3 Build/PQ3A.190801.002; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/76.0.3809.89 Mobile Safari/537.36", "pq3a.190801.002", "9"},
+		{"Mozilla/5.0 (Linux; Android 8.0.0; SM-G930F Build/R16NW) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/63.0.3239.111 Mobile Safari/537.36", "r16nw", "8.0.0"},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.OS.Name != "android" || ua.OS.Build != x.build || ua.OS.Version != x.version {
+			t.Errorf("%d: %s, expected: android %s (%s), got: %s %s (%s)\n", i, x.in, x.version, x.build, ua.OS.Name, ua.OS.Version, ua.OS.Build)
+		}
+	}
+}
+
+func TestOSVersion(t *testing.T) {
+	cases := []struct {
+		in, os, version string
+	}{
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "macosx", "10.15.7"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10.13; rv:121.0) Gecko/20100101 Firefox/121.0", "macosx", "10.13"},
+		// not a version, the player and the device follow Android
+		{"stagefright/1.2 (Linux;Android NexPlayer LG-E610GO20a-O2D-XX)", "android", ""},
+		{"Mozilla/5.0 (Linux; Android 4.4.2; Nexus 5 Build/KOT49H) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/34.0.1847.114 Mobile Safari/537.36", "android", "4.4.2"},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.OS.Name != x.os || ua.OS.Version != x.version {
+			t.Errorf("%d: %s, expected: %s %s, got: %s %s\n", i, x.in, x.os, x.version, ua.OS.Name, ua.OS.Version)
+		}
+	}
+}
+
+func TestWearOS(t *testing.T) {
+	cases := []struct {
+		in, version string
+	}{
+		{"Mozilla/5.0 (Linux; Android 11; Pixel Watch Build/RWD9.220429.053; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/104.0.5112.69 Mobile Safari/537.36", "3"},
+		{"Mozilla/5.0 (Linux; Android 13; SM-R930 Build/TP1A.220624.014) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/114.0.5735.196 Mobile Safari/537.36", "4"},
+		{"Mozilla/5.0 (Linux; Android 9; TicWatch Pro Build/PWDR.190618.001.A1) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/80.0.3987.99 Mobile Safari/537.36", ""},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.OS.Name != "wearos" || ua.OS.Version != x.version {
+			t.Errorf("%d: %s, expected: wearos %s, got: %s %s\n", i, x.in, x.version, ua.OS.Name, ua.OS.Version)
+		}
+	}
+}
+
+func TestTV(t *testing.T) {
+	cases := []struct {
+		in string
+		tv TV
+	}{
+		{"Mozilla/5.0 (SMART-TV; Linux; Tizen 5.0) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/2.2 Chrome/63.0.3239.84 TV Safari/537.36", TV{"samsung", "tizen", 2019}},
+		{"LG Browser/8.00.00 (webOS.TV-2017), _TV_M2R/05.80.02 (LG, 43LJ5500-SA, wireless),gzip(gfe)", TV{"lg", "webos", 2017}},
+		{"Mozilla/5.0 (X11; FreeBSD; U; Viera; de-DE) AppleWebKit/537.11 (KHTML, like Gecko) Viera/3.10.14 Chrome/23.0.1271.97 Safari/537.11", TV{"panasonic", "", 0}},
+		{"Mozilla/5.0 (X11; Linux i686) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/56.0.2924.0 Safari/537.36 Hisense/2.0 VIDAA/5.0", TV{"hisense", "vidaa", 0}},
+		{"Mozilla/5.0 (Linux; Android 9; BRAVIA 4K VH2 Build/PTT1.190515.001.S52) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.99 Mobile Safari/537.36 OPR/46.0.2207.140321", TV{"sony", "androidtv", 0}},
+		{"Mozilla/5.0 (Linux; Android 9; AFTMM Build/PS7233) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.110 Mobile Safari/537.36", TV{"", "fireos", 0}},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.97 Safari/537.11", TV{}},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.TV != x.tv {
+			t.Errorf("%d: %s, expected: %+v, got: %+v\n", i, x.in, x.tv, ua.TV)
+		}
+	}
+
+	// short brand keys only match whole words
+	words := []struct {
+		in    string
+		brand string
+	}{
+		{"lg, 43lj5500-sa, wireless", "lg"},
+		{"sony-bravia", "sony"},
+		{"tcl_smarttv", "tcl"},
+		{"lgbt tclient sonyericsson", ""},
+	}
+	for i, x := range words {
+		var tv TV
+		if tv.scan(x.in); tv.Brand != x.brand {
+			t.Errorf("%d: %s, expected: %s, got: %s\n", i, x.in, x.brand, tv.Brand)
+		}
+	}
+
+	// keys are scanned longest first, not in map order
+	if len(tvKeys) != len(tvTokens) {
+		t.Errorf("expected: %d keys, got: %d\n", len(tvTokens), len(tvKeys))
+	}
+	for i := 1; i < len(tvKeys); i++ {
+		if len(tvKeys[i]) > len(tvKeys[i-1]) {
+			t.Errorf("%d: %s before %s\n", i, tvKeys[i-1], tvKeys[i])
+		}
+	}
+}
+
+func TestHbbTV(t *testing.T) {
+	cases := []struct {
+		in   string
+		want HbbTV
+	}{
+		{"HbbTV/1.2.1 (+DRM;Samsung;SmartTV2015;T-HKM6DEUC-1490.3;;) Mozilla/5.0 (SMART-TV; Linux; Tizen 2.3) AppleWebKit/538.1 (KHTML, like Gecko) SamsungBrowser/1.0 TV Safari/538.1",
+			HbbTV{"1.2.1", []string{"drm"}, "samsung", "smarttv2015", "t-hkm6deuc-1490.3", "", ""}},
+		{"Mozilla/5.0 (Linux; Tizen 2.3) HbbTV/1.1.1 (+PVR+DL;Panasonic;VIERA 2011;1.261;0071-3103 2000-0000;)",
+			HbbTV{"1.1.1", []string{"pvr", "dl"}, "panasonic", "viera 2011", "1.261", "0071-3103 2000-0000", ""}},
+		{"Opera/9.80 (Linux mips; U; HbbTV/1.1.1 (; Philips; ; ; ; ) CE-HTML/1.0 NETTV/3.2.1; en) Presto/2.6.33 Version/10.70",
+			HbbTV{"1.1.1", nil, "philips", "", "", "", ""}},
+	}
+
+	for i, x := range cases {
+		h := Parse(x.in).HbbTV
+		if h == nil {
+			t.Errorf("%d: %s, expected: %+v, got: nil\n", i, x.in, x.want)
+			continue
+		}
+		if fmt.Sprint(*h) != fmt.Sprint(x.want) {
+			t.Errorf("%d: %s, expected: %+v, got: %+v\n", i, x.in, x.want, *h)
+		}
+	}
+
+	if h := Parse("HbbTV/1.2.1 (+DRM+TVA;LGE;43LJ5500;;;)").HbbTV; !h.Has("+DRM") || !h.Has("tva") || h.Has("pvr") {
+		t.Errorf("capabilities: %v\n", h.Capabilities)
+	}
+}
+
+func TestStarboard(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Starboard
+	}{
+		{"Mozilla/5.0 (RokuOS) Cobalt/9.174384-gold (unlike Gecko) Starboard/4, Roku_OTT_MC2/9.0 (Roku, 3900X, Wireless),gzip(gfe)",
+			Starboard{"4", "roku", "ott", "mc2", "", "9.0", "roku", "3900x", "wireless"}},
+		{"Mozilla/5.0 (Linux armv7l) Cobalt/20.lts.2.0-gold (unlike Gecko) v8/8.0.426.40-jit gles Starboard/11, LG_TV_M16P3_2020/05.20.26 (LG, 55UN7300AUD, wired)",
+			Starboard{"11", "lg", "tv", "m16p3", "2020", "05.20.26", "lg", "55un7300aud", "wired"}},
+		{"Mozilla/5.0 (X11; Linux x86_64) Cobalt/19.lts.4.196747-gold (unlike Gecko) v8/6.5.254.43 gles Starboard/10, _TV_M2R/05.80.02 (LG, 43LJ5500-SA, wireless),gzip(gfe)",
+			Starboard{"10", "", "tv", "m2r", "", "05.80.02", "lg", "43lj5500-sa", "wireless"}},
+	}
+
+	for i, x := range cases {
+		sb := Parse(x.in).Starboard
+		if sb == nil || *sb != x.want {
+			t.Errorf("%d: %s, expected: %+v, got: %+v\n", i, x.in, x.want, sb)
+		}
+	}
+}
+
+func TestRoku(t *testing.T) {
+	cases := []struct {
+		in                    string
+		version, build, model string
+		name                  string
+	}{
+		{"Roku/DVP-9.0 (289.00E04144A)", "9.0", "4144", "", ""},
+		{"Roku4640X/DVP-7.70 (297.70E04154A)", "7.70", "4154", "4640x", "ultra"},
+		{"Mozilla/5.0 (RokuOS) Cobalt/9.174384-gold (unlike Gecko) Starboard/4, Roku_OTT_MC2/9.0 (Roku, 3900X, Wireless),gzip(gfe)", "9.0", "", "3900x", "express"},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		var model, name string
+		if ua.Roku != nil {
+			model, name = ua.Roku.Model, ua.Roku.ModelName
+		}
+		if ua.OS.Version != x.version || ua.OS.Build != x.build || model != x.model || name != x.name {
+			t.Errorf("%d: %s, expected: %s %s %s %s, got: %s %s %s %s\n", i, x.in, x.version, x.build, x.model, x.name, ua.OS.Version, ua.OS.Build, model, name)
+		}
+	}
+}
+
+func TestChromium(t *testing.T) {
+	cases := []struct {
+		in                         string
+		browser, version, chromium string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91", "edge", "120.0.2210.91", "120.0.0.0"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 YaBrowser/23.11.0.0 Safari/537.36", "yabrowser", "23.11.0.0", "118.0.0.0"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Vivaldi/6.5.3206.48", "vivaldi", "6.5.3206.48", "120.0.0.0"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/118.0.0.0 Whale/3.23.214.10 Safari/537.36", "whale", "3.23.214.10", "118.0.0.0"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 OPR/106.0.0.0", "opr", "106.0.0.0", "120.0.0.0"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.97 Safari/537.11", "chrome", "23.0.1271.97", "23.0.1271.97"},
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "firefox", "121.0", ""},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.Browser.Name != x.browser || ua.Browser.Version != x.version || ua.Chromium != x.chromium {
+			t.Errorf("%d: %s, expected: %s %s (%s), got: %s %s (%s)\n", i, x.in, x.browser, x.version, x.chromium, ua.Browser.Name, ua.Browser.Version, ua.Chromium)
+		}
+	}
+}
+
+func TestEngine(t *testing.T) {
+	cases := []struct {
+		in                      string
+		engine, version, family string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.102 Safari/537.36 Edge/18.19582", "edgehtml", "18.19582", "edge"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91", "blink", "120.0.0.0", "chromium"},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.97 Safari/537.11", "webkit", "537.11", "chromium"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1", "webkit", "605.1.15", "chromium"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) FxiOS/121.0 Mobile/15E148 Safari/605.1.15", "webkit", "605.1.15", "firefox"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15", "webkit", "605.1.15", "safari"},
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "gecko", "121.0", "firefox"},
+		{"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko", "trident", "7.0", "ie"},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.Engine.Name != x.engine || ua.Engine.Version != x.version || ua.Browser.Family != x.family {
+			t.Errorf("%d: %s, expected: %s %s (%s), got: %s %s (%s)\n", i, x.in, x.engine, x.version, x.family, ua.Engine.Name, ua.Engine.Version, ua.Browser.Family)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	cases := []struct {
+		in     string
+		locale Locale
+	}{
+		{"com.google.android.youtube/14.08.55(Linux; U; Android 6.0; es_US; M4 SS4457 Build/MRA58K) gzip,gzip(gfe)", Locale{"es", "", "US"}},
+		{"Mozilla/5.0 (Linux; U; Android 4.0.3; zh-Hant-TW; HTC Sensation Build/IML74K) AppleWebKit/534.30 (KHTML, like Gecko) Version/4.0 Mobile Safari/534.30", Locale{"zh", "Hant", "TW"}},
+		{"Mozilla/5.0 (X11; FreeBSD; U; Viera; de-DE) AppleWebKit/537.11 (KHTML, like Gecko) Viera/3.10.14 Chrome/23.0.1271.97 Safari/537.11", Locale{"de", "", "DE"}},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 13_3_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 [FBAN/FBIOS;FBDV/iPhone11,8;FBMD/iPhone;FBSN/iOS;FBSV/13.3.1;FBSS/2;FBID/phone;FBLC/en_US;FBOP/5]", Locale{"en", "", "US"}},
+		{"Mozilla/5.0 (Linux; Android 10; SM-T510) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.99 Safari/537.36", Locale{}},
+		{"Opera/9.80 (Linux mips; U; HbbTV/1.1.1 (; Philips; ; ; ; ) CE-HTML/1.0 NETTV/3.2.1; en) Presto/2.6.33 Version/10.70", Locale{"en", "", ""}},
+		{"Mozilla/5.0 (Linux; Android 9; ID; Build/PPR1.180610.011) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/74.0.3729.136 Mobile Safari/537.36", Locale{}},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.Locale != x.locale || ua.Language != x.locale.String() {
+			t.Errorf("%d: %s, expected: %+v, got: %+v (%s)\n", i, x.in, x.locale, ua.Locale, ua.Language)
+		}
+	}
+
+	h := http.Header{}
+	h.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.97 Safari/537.11")
+	h.Set("Accept-Language", "fr;q=0.8, pt-PT, en;q=0.5")
+	if ua := ParseHeaders(h); ua.Language != "pt-PT" {
+		t.Errorf("Accept-Language: expected pt-PT, got: %s\n", ua.Language)
+	}
+	h.Set("Accept-Language", "de;q=0, en-gb;q=0.5")
+	if ua := ParseHeaders(h); ua.Language != "en-GB" {
+		t.Errorf("Accept-Language: expected en-GB, got: %s\n", ua.Language)
+	}
+	h.Set("Accept-Language", "de;q=0")
+	if ua := ParseHeaders(h); ua.Language != "" {
+		t.Errorf("Accept-Language: expected none, got: %s\n", ua.Language)
+	}
+	h.Set("Accept-Language", "x-pig-latin-1234567890, fr-CA;q=0.9, en;q=0.8")
+	if ua := ParseHeaders(h); ua.Language != "fr-CA" {
+		t.Errorf("Accept-Language: expected fr-CA, got: %s\n", ua.Language)
+	}
+}
+
+func TestArch(t *testing.T) {
+	cases := []struct {
+		in, arch, osArch string
+		bits             int
+		version          string
+	}{
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.97 Safari/537.11", "x86_64", "x86_64", 64, ""},
+		{"Mozilla/5.0 (X11; Linux i686 on x86_64; rv:121.0) Gecko/20100101 Firefox/121.0", "x86", "x86_64", 32, ""},
+		{"Mozilla/5.0 (X11; Linux armv7l) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/78.0.3904.108 Safari/537.36 CrKey/1.44.191160", "arm", "arm", 32, ""},
+		{"Mozilla/5.0 (X11; Linux aarch64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/85.0.4183.134 Safari/537.36", "arm64", "arm64", 64, ""},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", "x86_64", "x86_64", 64, "10.0"},
+		{"Mozilla/5.0 (Windows NT 6.1; WOW64; Trident/7.0; rv:11.0) like Gecko", "x86", "x86_64", 32, "6.1"},
+		{"Opera/9.80 (Linux mips; U; HbbTV/1.1.1 (; Philips; ; ; ; ) CE-HTML/1.0 NETTV/3.2.1; en) Presto/2.6.33 Version/10.70", "mips", "mips", 32, ""},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.Arch != x.arch || ua.OSArch != x.osArch || ua.Bits != x.bits || ua.OS.Version != x.version {
+			t.Errorf("%d: %s, expected: %s/%s %d (%s), got: %s/%s %d (%s)\n", i, x.in, x.arch, x.osArch, x.bits, x.version, ua.Arch, ua.OSArch, ua.Bits, ua.OS.Version)
+		}
+	}
+}
+
+func TestFlags(t *testing.T) {
+	ua := Parse("Mozilla/5.0 (Linux; Android 11; Pixel 5 Build/RQ3A.210805.001.A1; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/92.0.4515.159 Mobile Safari/537.36")
+	if !ua.IsMobile() || !ua.IsWebView() || ua.IsNativeApp() || ua.IsConnectedTV() {
+		t.Errorf("webview: %+v\n", ua)
+	}
+
+	ua = Parse("Mozilla/5.0 (Linux; Pixel 3 Build/PQ3A.190801.002; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/76.0.3809.89 Mobile Safari/537.36")
+	if !ua.IsWebView() {
+		t.Errorf("webview without android: %+v\n", ua)
+	}
+
+	ua = Parse("okhttp/3.12.1")
+	if !ua.IsNativeApp() || !ua.HasTag("OkHttp") || ua.IsMobile() {
+		t.Errorf("okhttp: %+v\n", ua)
+	}
+
+	ua = Parse("AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0")
+	if !ua.IsNativeApp() || !ua.HasTag("cfnetwork") {
+		t.Errorf("cfnetwork: %+v\n", ua)
+	}
+
+	ua = Parse("Mozilla/5.0 (Linux; Android 9; SHIELD Android TV Build/PPR1.180610.011; CTV) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/80.0.3987.99 Safari/537.36")
+	if !ua.IsConnectedTV() || !ua.HasTag("ctv") || !ua.HasTag("smarttv") || ua.IsSetTopBox() {
+		t.Errorf("ctv: %+v\n", ua)
+	}
+
+	ua = Parse("Mozilla/5.0 (Linux; STB; Android 7.1.1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/66.0.3359.158 Safari/537.36")
+	if !ua.IsSetTopBox() || !ua.HasTag("stb") {
+		t.Errorf("stb: %+v\n", ua)
+	}
+}
+
+func TestClient(t *testing.T) {
+	cases := []struct {
+		in, client, version string
+		os, osVersion       string
+	}{
+		{"AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0", "cfnetwork", "1220.1", "ios", "14.4"},
+		{"iPad6,11/13.4 CFNetwork/1121.2.2 Darwin/19.3.0", "cfnetwork", "1121.2.2", "ios", "13.3.1"},
+		{"okhttp/3.12.1", "okhttp", "3.12.1", "", ""},
+		{"Dalvik/2.1.0 (Linux; U; Android 9; SM-G960F Build/PPR1.180610.011)", "dalvik", "2.1.0", "android", "9"},
+		{"AppleCoreMedia/1.0.0.17E262 (iPhone; U; CPU OS 13_4 like Mac OS X; en_us)", "applecoremedia", "1.0.0.17e262", "ios", "13.4"},
+		{"curl/7.68.0", "curl", "7.68.0", "", ""},
+		{"Java/1.8.0_151", "java", "1.8.0_151", "", ""},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.11 (KHTML, like Gecko) Chrome/23.0.1271.97 Safari/537.11", "", "", "linux", ""},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.Client.Name != x.client || ua.Client.Version != x.version || ua.OS.Name != x.os || ua.OS.Version != x.osVersion {
+			t.Errorf("%d: %s, expected: %s %s (%s %s), got: %s %s (%s %s)\n", i, x.in, x.client, x.version, x.os, x.osVersion, ua.Client.Name, ua.Client.Version, ua.OS.Name, ua.OS.Version)
+		}
+		if ua.IsNativeApp() != (x.client != "") {
+			t.Errorf("%d: %s, IsNativeApp: %v\n", i, x.in, ua.IsNativeApp())
+		}
+	}
+}
+
+func TestDarwin(t *testing.T) {
+	cases := []struct {
+		in, os, version, kernel string
+		deviceType              int
+	}{
+		{"AppName/1.0 CFNetwork/1220.1 Darwin/20.3.0", "ios", "14.4", "20.3.0", Phone},
+		{"iPad8,1/15.4 CFNetwork/1331.0.7 Darwin/21.4.0", "ios", "15.4", "21.4.0", Tablet},
+		{"AppName/2 CFNetwork/711.1.16 Darwin/14.0.0", "ios", "8.1", "14.0.0", Phone},
+		{"AppName/2 CFNetwork/672.0.8 Darwin/14.0.0", "ios", "7.0", "14.0.0", Phone},
+		{"AppleTV11,1/16.1 CFNetwork/1399 Darwin/22.1.0", "tvos", "16.1", "22.1.0", SmartTV},
+		{"Watch6,1/9.1 CFNetwork/1399 Darwin/22.1.0", "watchos", "9", "22.1.0", Wearable},
+		{"AppName/1.0 CFNetwork/1220.1.2 Darwin/20.3.0 (x86_64)", "macosx", "11.2", "20.3.0", Desktop},
+		{"AppName/3.1 CFNetwork/1128.0.1 Darwin/19.6.0 (x86_64)", "macosx", "10.15.6", "19.6.0", Desktop},
+		{"AppName/1.0 CFNetwork/1568.100.1 Darwin/24.0.0", "ios", "18.0", "24.0.0", Phone},
+		{"AppName/1.0 CFNetwork/1000 Darwin/99.0.0", "ios", "", "99.0.0", Phone},
+	}
+
+	for i, x := range cases {
+		ua := Parse(x.in)
+		if ua.OS.Name != x.os || ua.OS.Version != x.version || ua.Kernel.Name != "darwin" || ua.Kernel.Version != x.kernel || ua.DeviceType != x.deviceType {
+			t.Errorf("%d: %s, expected: %d %s %s (darwin %s), got: %d %s %s (%s %s)\n", i, x.in, x.deviceType, x.os, x.version, x.kernel, ua.DeviceType, ua.OS.Name, ua.OS.Version, ua.Kernel.Name, ua.Kernel.Version)
+		}
+	}
+}
+
+func TestParser(t *testing.T) {
+	p := NewParser()
+	if err := p.AddRule(Rule{Token: "MyApp", Type: CLIENT, Priority: 2, Source: IN_PRODUCT}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddRule(Rule{Token: "mybox", Type: DEVICE, Priority: 3, Source: IN_COMMENT, DeviceType: SetTop}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddRule(Rule{Token: "x", Type: LANGUAGE, Source: IN_BOTH}); err != ErrInvalidRule {
+		t.Errorf("expected ErrInvalidRule, got %v\n", err)
+	}
+	if err := p.AddRule(Rule{Token: "", Type: OS, Source: IN_BOTH}); err != ErrInvalidRule {
+		t.Errorf("expected ErrInvalidRule, got %v\n", err)
+	}
+
+	s := "MyApp/2.1 (Linux; MyBox)"
+	ua := p.Parse(s)
+	if ua.Client.Name != "myapp" || ua.Client.Version != "2.1" || ua.Device.Name != "mybox" || ua.DeviceType != SetTop {
+		t.Errorf("%s, got: %+v\n", s, ua)
+	}
+	if ua = Parse(s); ua.Client.Name != "" || ua.Device.Name != "" {
+		t.Errorf("%s, rules leaked into Parse: %+v\n", s, ua)
+	}
+
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 100; i++ {
+			p.AddRule(Rule{Token: fmt.Sprintf("app%d", i), Type: CLIENT, Priority: 1, Source: IN_PRODUCT})
+		}
+		done <- true
+	}()
+	for i := 0; i < 100; i++ {
+		p.Parse(s)
+	}
+	<-done
+	if ua = p.Parse("App42/1.0"); ua.Client.Name != "app42" {
+		t.Errorf("app42, got: %+v\n", ua.Client)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	b, err := json.Marshal(Parse("okhttp/3.12.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"device_type":0,"os":{},"browser":{},"device":{},"engine":{},"client":{"name":"okhttp","version":"3.12.1","provenance":"token"},"kernel":{},"locale":{},"tv":{},"mobile":false,"webview":false,"native_app":true,"connected_tv":false,"set_top_box":false}`
+	if string(b) != expected {
+		t.Errorf("expected: %s, got: %s\n", expected, b)
+	}
+}
+
+func TestPrefixTrie(t *testing.T) {
+	var tried []string
+	handler := func(accept bool) func(*UserAgent, *recognizer, *section) bool {
+		return func(ua *UserAgent, reco *recognizer, sec *section) bool {
+			tried = append(tried, fmt.Sprintf("%s:%d", reco.prefix, reco.priority))
+			return accept
+		}
+	}
+
+	trie := newPrefixTrie()
+	trie.add(&recognizer{prefix: "windows ", priority: 1, handler: handler(true)})
+	trie.add(&recognizer{prefix: "windows nt ", priority: 1, handler: handler(false)})
+	trie.add(&recognizer{prefix: "windows nt ", priority: 2, handler: handler(false)})
+	trie.add(&recognizer{prefix: "win", priority: 1, handler: handler(true)})
+	trie.add(&recognizer{prefix: "", priority: 9, handler: handler(true)})
+
+	cases := []struct {
+		in, tried string
+		ok        bool
+	}{
+		{"windows nt 10.0", "windows nt :2,windows nt :1,windows :1", true},
+		{"windows 98", "windows :1", true},
+		{"winamp", "win:1", true},
+		{"linux", "", false},
+	}
+	for i, x := range cases {
+		tried = nil
+		ok := trie.try(&UserAgent{}, &section{name: x.in}, x.in)
+		if got := strings.Join(tried, ","); ok != x.ok || got != x.tried {
+			t.Errorf("%d: %s, expected: %v %s, got: %v %s\n", i, x.in, x.ok, x.tried, ok, got)
+		}
+	}
+}
+
+func TestResolution(t *testing.T) {
+	p := NewParser()
+	p.AddRule(Rule{Token: "alpha", Type: BROWSER, Priority: 2, Source: IN_PRODUCT})
+	p.AddRule(Rule{Token: "beta", Type: BROWSER, Priority: 2, Source: IN_PRODUCT})
+	p.AddRule(Rule{Token: "gamma", Type: BROWSER, Priority: 1, Source: IN_PRODUCT})
+
+	s := "Alpha/1.0 Beta/2.0 Gamma/3.0"
+	ua := p.Parse(s)
+	expected := []Conflict{{"browser", "beta", "2.0", "alpha", "position"}, {"browser", "gamma", "3.0", "alpha", "priority"}}
+	if ua.Browser.Name != "alpha" || !reflect.DeepEqual(ua.Conflicts, expected) {
+		t.Errorf("%s, expected: alpha %v, got: %s %v\n", s, expected, ua.Browser.Name, ua.Conflicts)
+	}
+
+	if err := p.SetResolution(ByPriority, ByLaterPosition); err != nil {
+		t.Fatal(err)
+	}
+	ua = p.Parse(s)
+	expected = []Conflict{{"browser", "alpha", "1.0", "beta", "position"}, {"browser", "gamma", "3.0", "beta", "priority"}}
+	if ua.Browser.Name != "beta" || !reflect.DeepEqual(ua.Conflicts, expected) {
+		t.Errorf("%s, expected: beta %v, got: %s %v\n", s, expected, ua.Browser.Name, ua.Conflicts)
+	}
+	if err := p.SetResolution(); err != ErrInvalidResolution {
+		t.Errorf("expected ErrInvalidResolution, got %v\n", err)
+	}
+
+	exact := candidate{priority: 1, pos: 3, specificity: newCandidate(&section{}, &recognizer{}).specificity}
+	prefix := candidate{priority: 1, pos: 3, specificity: len("windows nt ")}
+	shorter := candidate{priority: 1, pos: 3, specificity: len("windows ")}
+	if replace, by, ok := beats(DefaultResolution, exact, prefix); !replace || by != BySpecificity || !ok {
+		t.Errorf("exact token should beat a prefix\n")
+	}
+	if replace, _, _ := beats(DefaultResolution, shorter, prefix); replace {
+		t.Errorf("a shorter prefix should not beat a longer one\n")
+	}
+	if replace, _, ok := beats(DefaultResolution, prefix, prefix); replace || ok {
+		t.Errorf("ties keep the current value\n")
+	}
+}
+
+func TestProvenance(t *testing.T) {
+	tests := []struct {
+		s                         string
+		deviceType, device, os, b Provenance
+	}{
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1", ExplicitToken, ExplicitToken, PrefixRule, ExplicitToken},
+		{"Mozilla/5.0 (Linux; Android 13; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", Default, Heuristic, PrefixRule, ExplicitToken},
+		{"Mozilla/5.0 (Linux; Android 9; AFTMM Build/PS7233) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.110 Mobile Safari/537.36", PrefixRule, Heuristic, PrefixRule, ExplicitToken},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", Heuristic, NotDetected, PrefixRule, ExplicitToken},
+		{"AndroidApp/3.1 (Linux; U)", Heuristic, NotDetected, Heuristic, NotDetected},
+		{"MyApp/1.2 CFNetwork/1240.0.4 Darwin/20.5.0", Default, NotDetected, Heuristic, NotDetected},
+		{"Mozilla/5.0 (Linux; Tizen 2.3) AppleWebKit/538.1 (KHTML, like Gecko)Version/2.3 TV Safari/538.1", Heuristic, NotDetected, PrefixRule, ExplicitToken},
+		{"Viafree-tvOS-DK/3.7.1 (com.MTGx.ViaFree.dk; build:7341; tvOS 12.1.0) Alamofire/4.7.0", ExplicitToken, Heuristic, PrefixRule, NotDetected},
+		{"Mozilla/5.0 (PlayStation 5 3.11) AppleWebKit/605.1.15 (KHTML, like Gecko)", PrefixRule, PrefixRule, NotDetected, NotDetected},
+	}
+
+	for i, tt := range tests {
+		ua := Parse(tt.s)
+		got := []Provenance{ua.DeviceTypeProvenance, ua.Device.Provenance, ua.OS.Provenance, ua.Browser.Provenance}
+		expected := []Provenance{tt.deviceType, tt.device, tt.os, tt.b}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("%d: %s, expected: %v, got: %v\n", i, tt.s, expected, got)
+		}
+	}
+
+	var p Provenance
+	if err := p.UnmarshalText([]byte("prefix")); err != nil || p != PrefixRule {
+		t.Errorf("expected: prefix, got: %v %v\n", p, err)
+	}
+	if err := p.UnmarshalText([]byte("guess")); err != ErrInvalidProvenance {
+		t.Errorf("expected ErrInvalidProvenance, got %v\n", err)
+	}
+}
@@ -10,7 +10,7 @@ func handle_rv(ua *UserAgent, reco *recognizer, sec *section) bool {
 }
 
 func handle_smarttv(ua *UserAgent, reco *recognizer, sec *section) bool {
-	ua.DeviceType = SmartTV
+	ua.setType(SmartTV, provenanceOf(reco))
 	return true
 }
 
@@ -18,7 +18,7 @@ func handle_smarttv(ua *UserAgent, reco *recognizer, sec *section) bool {
 func handle_browser_version(ua *UserAgent, reco *recognizer, sec *section) bool {
 	sec.version = strings.TrimSpace(strings.TrimPrefix(sec.name, reco.prefix))
 	sec.name = reco.rewrite
-	ua.Browser.use(sec, reco)
+	ua.use("browser", &ua.Browser, sec, reco)
 	return true
 }
 
@@ -26,20 +26,74 @@ func handle_browser_version(ua *UserAgent, reco *recognizer, sec *section) bool
 func handle_device_version(ua *UserAgent, reco *recognizer, sec *section) bool {
 	sec.version = strings.TrimSpace(strings.TrimPrefix(sec.name, reco.prefix))
 	sec.name = reco.rewrite
-	if ua.Device.use(sec, reco) {
+	if ua.use("device", &ua.Device, sec, reco) {
 		if reco.deviceType > 0 {
-			ua.DeviceType = reco.deviceType
+			ua.setType(reco.deviceType, provenanceOf(reco))
 		}
 	}
 
 	return true
 }
 
+// Roku4640X/DVP-7.70 (297.70E04154A), the rest is picked up by useRoku
+func handle_roku(ua *UserAgent, reco *recognizer, sec *section) bool {
+	if !strings.HasPrefix(sec.version, "dvp-") {
+		return false
+	}
+	if ua.use("device", &ua.Device, &section{name: reco.rewrite, pos: sec.pos}, reco) {
+		ua.setType(reco.deviceType, provenanceOf(reco))
+	}
+	return true
+}
+
+// Cast devices tell what they are after CrKey:
+// Mozilla/5.0 (Fuchsia) ... CrKey/1.56.500000 DeviceType/SmartDisplay
+func handle_cast_device_type(ua *UserAgent, reco *recognizer, sec *section) bool {
+	var name string
+	var deviceType int
+	switch sec.version {
+	case "smartdisplay":
+		name, deviceType = "nesthub", SmartDisplay
+	case "smartspeaker":
+		name, deviceType = "nestspeaker", SmartSpeaker
+	case "chromecast":
+		name, deviceType = "chromecast", SmartTV
+	case "androidtv":
+		name, deviceType = "googletv", SmartTV
+	default:
+		return false
+	}
+
+	if ua.use("device", &ua.Device, &section{name: name, pos: sec.pos}, reco) {
+		ua.setType(deviceType, provenanceOf(reco))
+	}
+	return true
+}
+
 // Mozilla/5.0 (Windows NT 6.3; Trident/7.0; .NET4.0E; .NET4.0C; rv:11.0)
+// Mozilla/5.0 (X11; Linux i686 on x86_64; rv:121.0), the architecture is not a version
 func handle_os_version(ua *UserAgent, reco *recognizer, sec *section) bool {
-	sec.version = strings.TrimSpace(strings.TrimPrefix(sec.name, reco.prefix))
+	version, arch, osArch := splitArch(strings.TrimSpace(strings.TrimPrefix(sec.name, reco.prefix)))
+	if arch == "" {
+		arch = osArch
+	}
+	if arch != "" {
+		ua.setArch(arch)
+	}
+	if osArch != "" {
+		ua.OSArch = archs[osArch]
+	}
+	sec.version = version
 	sec.name = reco.rewrite
-	ua.OS.use(sec, reco)
+	switch sec.name {
+	case "macosx": // Intel Mac OS X 10_15_7
+		sec.version = strings.Replace(sec.version, "_", ".", -1)
+	case "android": // stagefright/1.2 (Linux;Android NexPlayer LG-E610GO20a-O2D-XX)
+		if sec.version != "" && (sec.version[0] < '0' || sec.version[0] > '9') {
+			sec.version = ""
+		}
+	}
+	ua.use("os", &ua.OS, sec, reco)
 	return true
 }
 
@@ -47,11 +101,11 @@ func handle_os_version(ua *UserAgent, reco *recognizer, sec *section) bool {
 func handle_ios(ua *UserAgent, reco *recognizer, sec *section) bool {
 	name := strings.TrimPrefix(sec.name, reco.prefix)
 	name = strings.TrimSuffix(name, "like mac os x")
-	sec.version = strings.TrimSpace(name)
+	sec.version = strings.Replace(strings.TrimSpace(name), "_", ".", -1)
 	sec.name = reco.rewrite
 	if sec.name == "" {
 		sec.name = "ios"
 	}
-	ua.OS.use(sec, reco)
+	ua.use("os", &ua.OS, sec, reco)
 	return true
 }
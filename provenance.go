@@ -0,0 +1,62 @@
+package uaparser
+
+import "errors"
+
+// Provenance tells how DeviceType, Device, OS or Browser was detected,
+// ordered from the most to the least reliable. Filter on
+// p != NotDetected && p <= PrefixRule to keep only token based results.
+type Provenance int
+
+const (
+	NotDetected   Provenance = iota
+	ExplicitToken            // a token of the tables, e.g. iPhone, Chrome/120.0, or a structured HbbTV or Starboard field
+	PrefixRule               // a prefix match, e.g. Android 11, Windows NT 10.0, AFTMM
+	Heuristic                // a guess, e.g. the last Android comment is the device, an app name starting with iPad or Android
+	Default                  // a fallback, e.g. Android without a device type is a phone
+)
+
+var provenanceNames = []string{"", "token", "prefix", "heuristic", "default"}
+
+var ErrInvalidProvenance = errors.New("uaparser: invalid provenance")
+
+func (p Provenance) String() string {
+	if p < 0 || int(p) >= len(provenanceNames) {
+		return "unknown"
+	}
+	return provenanceNames[p]
+}
+
+func (p Provenance) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *Provenance) UnmarshalText(b []byte) error {
+	for i, x := range provenanceNames {
+		if x == string(b) {
+			*p = Provenance(i)
+			return nil
+		}
+	}
+	return ErrInvalidProvenance
+}
+
+// provenanceOf a recognizer match, nil is a heuristic guess
+func provenanceOf(reco *recognizer) Provenance {
+	switch {
+	case reco == nil:
+		return Heuristic
+	case reco.prefix != "":
+		return PrefixRule
+	}
+	return ExplicitToken
+}
+
+func (ua *UserAgent) setType(t int, p Provenance) {
+	ua.DeviceType = t
+	ua.DeviceTypeProvenance = p
+}
+
+func (c *Component) set(name string, p Provenance) {
+	c.Name = name
+	c.Provenance = p
+}
@@ -0,0 +1,140 @@
+package uaparser
+
+import (
+	"errors"
+	"math"
+)
+
+// Criterion is one step in deciding between two candidates for the same
+// component, e.g. Safari/604.1 and Chrome/120.0 for Browser.
+type Criterion int
+
+const (
+	ByPriority      Criterion = iota // higher recognizer priority wins
+	ByPosition                       // the earlier token in the UA wins
+	ByLaterPosition                  // the later token in the UA wins
+	BySpecificity                    // an exact token beats a prefix, a longer prefix a shorter one
+)
+
+// DefaultResolution is used by Parse and new Parsers: priority, then
+// position, then specificity. When every criterion ties the current value
+// is kept.
+var DefaultResolution = []Criterion{ByPriority, ByPosition, BySpecificity}
+
+var ErrInvalidResolution = errors.New("uaparser: invalid resolution")
+
+func (c Criterion) String() string {
+	switch c {
+	case ByPriority:
+		return "priority"
+	case ByPosition, ByLaterPosition:
+		return "position"
+	case BySpecificity:
+		return "specificity"
+	}
+	return "unknown"
+}
+
+// Conflict is a candidate that lost, Reason names the deciding criterion
+type Conflict struct {
+	Field   string `json:"field"` // os, browser, device, engine, client
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Winner  string `json:"winner"`
+	Reason  string `json:"reason"`
+}
+
+// candidate is the part of a recognizer match that takes part in resolution
+type candidate struct {
+	priority, pos, specificity int
+}
+
+func newCandidate(sec *section, reco *recognizer) candidate {
+	c := candidate{priority: reco.priority, pos: sec.pos, specificity: math.MaxInt32}
+	if reco.prefix != "" {
+		c.specificity = len(reco.prefix)
+	}
+	return c
+}
+
+// beats reports whether a replaces the current value b and the criterion
+// that decided it, ok is false when all criteria tie
+func beats(order []Criterion, a, b candidate) (replace bool, by Criterion, ok bool) {
+	for _, x := range order {
+		var d int
+		switch x {
+		case ByPriority:
+			d = a.priority - b.priority
+		case ByPosition:
+			d = b.pos - a.pos
+		case ByLaterPosition:
+			d = a.pos - b.pos
+		case BySpecificity:
+			d = a.specificity - b.specificity
+		}
+		if d != 0 {
+			return d > 0, x, true
+		}
+	}
+	return false, 0, false
+}
+
+// use offers sec to the component c named field. Without a recognizer sec
+// is a heuristic guess and only fills an empty component.
+func (ua *UserAgent) use(field string, c *Component, sec *section, reco *recognizer) bool {
+	if reco == nil {
+		if c.Name != "" {
+			return false
+		}
+		c.Name = sec.name
+		c.Version = sec.version
+		c.Provenance = Heuristic
+		return true
+	}
+
+	a := newCandidate(sec, reco)
+	if c.Name == "" {
+		c.Name, c.Version, c.candidate = sec.name, sec.version, a
+		c.Provenance = provenanceOf(reco)
+		return true
+	}
+
+	order := DefaultResolution
+	if ua.rules != nil && ua.rules.resolution != nil {
+		order = ua.rules.resolution
+	}
+	replace, by, ok := beats(order, a, c.candidate)
+	reason := "tie"
+	if ok {
+		reason = by.String()
+	}
+
+	loser, winner := Conflict{Field: field, Name: sec.name, Version: sec.version, Winner: c.Name}, sec.name
+	if replace {
+		loser.Name, loser.Version, loser.Winner = c.Name, c.Version, winner
+		c.Name, c.Version, c.candidate = sec.name, sec.version, a
+		c.Provenance = provenanceOf(reco)
+	}
+	if loser.Name != loser.Winner {
+		loser.Reason = reason
+		ua.Conflicts = append(ua.Conflicts, loser)
+	}
+	return replace
+}
+
+// SetResolution replaces the criteria deciding between candidates, e.g.
+// SetResolution(ByPriority, ByLaterPosition)
+func (p *Parser) SetResolution(order ...Criterion) error {
+	if len(order) == 0 {
+		return ErrInvalidResolution
+	}
+	for _, x := range order {
+		if x < ByPriority || x > BySpecificity {
+			return ErrInvalidResolution
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resolution = append([]Criterion(nil), order...)
+	return nil
+}
@@ -0,0 +1,113 @@
+package uaparser
+
+import (
+	"strings"
+)
+
+// Roku is the hardware model of a Roku player or Roku TV
+type Roku struct {
+	Model     string `json:"model"`                // model code, e.g. 3900x
+	ModelName string `json:"model_name,omitempty"` // e.g. express
+}
+
+var rokuModels = map[string]string{
+	"2700x": "lt",
+	"2710x": "roku 1",
+	"2720x": "roku 2",
+	"3000x": "roku 2 hd",
+	"3050x": "roku 2 xd",
+	"3100x": "roku 2 xs",
+	"3400x": "streaming stick",
+	"3420x": "streaming stick",
+	"3500x": "streaming stick",
+	"3600x": "streaming stick",
+	"3700x": "express",
+	"3710x": "express+",
+	"3800x": "streaming stick",
+	"3810x": "streaming stick+",
+	"3811x": "streaming stick+",
+	"3820x": "streaming stick 4k",
+	"3821x": "streaming stick 4k+",
+	"3900x": "express",
+	"3910x": "express+",
+	"3930x": "express",
+	"3931x": "express+",
+	"3940x": "express 4k",
+	"3941x": "express 4k+",
+	"4200x": "roku 3",
+	"4210x": "roku 2",
+	"4230x": "roku 3",
+	"4400x": "roku 4",
+	"4620x": "premiere",
+	"4630x": "premiere+",
+	"4640x": "ultra",
+	"4660x": "ultra",
+	"4661x": "ultra",
+	"4662x": "ultra lt",
+	"4670x": "ultra",
+	"4800x": "ultra",
+	"4802x": "ultra",
+	"5000x": "roku tv",
+	"6000x": "roku tv",
+	"7000x": "roku tv",
+	"8000x": "roku tv",
+	"9100x": "smart soundbar",
+	"9101x": "streambar",
+	"9102x": "streambar pro",
+}
+
+// rokuBuild returns the build number of a firmware tag like 289.00e04144a
+func rokuBuild(tag string) string {
+	i := strings.LastIndexByte(tag, 'e')
+	if i < 0 || strings.IndexByte(tag, '.') < 0 {
+		return ""
+	}
+	j := i + 1
+	for j < len(tag) && isDigit(tag[j]) {
+		j++
+	}
+	return strings.TrimLeft(tag[i+1:j], "0")
+}
+
+// useRoku fills in Roku OS version, build and model from
+// Roku/DVP-9.0 (289.00E04144A), Roku4640X/DVP-7.70 (297.70E04154A)
+// or the Cobalt device string Roku_OTT_MC2/9.0 (Roku, 3900X, Wireless)
+func (ua *UserAgent) useRoku(products []product) {
+	var model string
+	for _, p := range products {
+		if p.section == nil || !strings.HasPrefix(p.section.name, "roku") || !strings.HasPrefix(p.section.version, "dvp-") {
+			continue
+		}
+		ua.OS.set("rokuos", ExplicitToken)
+		ua.OS.Version = strings.TrimPrefix(p.section.version, "dvp-")
+		if len(p.comment) > 0 {
+			ua.OS.Build = rokuBuild(p.comment[0].name)
+		}
+		model = strings.TrimSpace(strings.TrimPrefix(p.section.name, "roku"))
+		break
+	}
+
+	if sb := ua.Starboard; sb != nil && sb.OEM == "roku" {
+		ua.OS.set("rokuos", ExplicitToken)
+		if ua.OS.Version == "" {
+			ua.OS.Version = sb.Firmware
+		}
+		if model == "" {
+			model = sb.Model
+		}
+	}
+
+	// a Roku product names the device, rokuos alone only implies it
+	p := ua.Device.Provenance
+	if p == NotDetected {
+		p = Heuristic
+	}
+	ua.Device.set("roku", p)
+	ua.Device.Version = ""
+	if ua.Device.Brand == "" {
+		ua.Device.Brand = "roku"
+	}
+	if model != "" {
+		ua.Roku = &Roku{Model: model, ModelName: rokuModels[model]}
+	}
+}
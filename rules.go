@@ -0,0 +1,87 @@
+package uaparser
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var ErrInvalidRule = errors.New("uaparser: invalid rule")
+
+// Rule maps a token to a component on top of the built-in
+// tables, e.g. Rule{Token: "myapp", Type: CLIENT, Priority: 2, Source: IN_PRODUCT}
+type Rule struct {
+	Token      string
+	Type       int // OS, BROWSER, DEVICE, ENGINE, CLIENT or SKIP
+	Priority   int
+	Source     int // IN_PRODUCT, IN_COMMENT or IN_BOTH
+	DeviceType int // for DEVICE rules
+	Rewrite    string
+}
+
+// Parser parses with a set of custom rules. Rules take precedence over
+// the built-in tables for the same token. A Parser is safe for concurrent
+// use, rules can be added while parsing.
+type Parser struct {
+	mu               sync.RWMutex
+	product, comment map[string]*recognizer
+	resolution       []Criterion
+}
+
+// NewParser returns a Parser without custom rules
+func NewParser() *Parser {
+	return &Parser{
+		product: make(map[string]*recognizer),
+		comment: make(map[string]*recognizer),
+	}
+}
+
+// AddRule adds or replaces the rule for r.Token
+func (p *Parser) AddRule(r Rule) error {
+	switch r.Type {
+	case OS, BROWSER, DEVICE, ENGINE, CLIENT, SKIP:
+	default:
+		return ErrInvalidRule
+	}
+	if r.Token == "" || r.Source&IN_BOTH == 0 {
+		return ErrInvalidRule
+	}
+
+	token := strings.ToLower(r.Token)
+	reco := &recognizer{typ: r.Type, priority: r.Priority, deviceType: r.DeviceType, rewrite: strings.ToLower(r.Rewrite)}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if (r.Source & IN_PRODUCT) != 0 {
+		p.product[token] = reco
+	}
+	if (r.Source & IN_COMMENT) != 0 {
+		p.comment[token] = reco
+	}
+	return nil
+}
+
+func (p *Parser) lookup(name string, isProduct bool) (*recognizer, bool) {
+	var reco *recognizer
+	var ok bool
+	if isProduct {
+		reco, ok = p.product[name]
+	} else {
+		reco, ok = p.comment[name]
+	}
+	return reco, ok
+}
+
+// Parse is Parse with the custom rules of p
+func (p *Parser) Parse(s string) *UserAgent {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return parseWith(s, p)
+}
+
+// ParseHeaders is ParseHeaders with the custom rules of p
+func (p *Parser) ParseHeaders(h http.Header) *UserAgent {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return parseHeaders(h, p)
+}
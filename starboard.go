@@ -0,0 +1,91 @@
+package uaparser
+
+import (
+	"strings"
+)
+
+// Starboard is the device string Cobalt (YouTube on living room devices)
+// appends to its UA:
+// Starboard/4, <oem>_<type>_<chipset>[_<year>]/<firmware> (<brand>, <model>, <connection>)
+type Starboard struct {
+	Version    string `json:"version,omitempty"` // starboard API version
+	OEM        string `json:"oem"`
+	Type       string `json:"type"` // tv, ott, atv, stb, game, bdp
+	Chipset    string `json:"chipset,omitempty"`
+	Year       string `json:"year,omitempty"`
+	Firmware   string `json:"firmware,omitempty"`
+	Brand      string `json:"brand,omitempty"`
+	Model      string `json:"model,omitempty"`
+	Connection string `json:"connection,omitempty"` // wired, wireless
+}
+
+var starboardDeviceTypes = map[string]int{
+	"tv":   SmartTV,
+	"atv":  SmartTV,
+	"ott":  SmartTV,
+	"stb":  SetTop,
+	"bdp":  SetTop,
+	"game": Console,
+}
+
+// parseStarboard recognizes Roku_OTT_MC2/9.0 (Roku, 3900X, Wireless)
+// and _TV_M2R/05.80.02 (LG, 43LJ5500-SA, wireless)
+func parseStarboard(p product) *Starboard {
+	if p.section == nil {
+		return nil
+	}
+	names := strings.Split(p.section.name, "_")
+	if len(names) < 2 {
+		return nil
+	}
+	if _, ok := starboardDeviceTypes[names[1]]; !ok {
+		return nil
+	}
+
+	sb := &Starboard{OEM: names[0], Type: names[1], Firmware: p.section.version}
+	if len(names) > 2 {
+		sb.Chipset = names[2]
+	}
+	if len(names) > 3 {
+		sb.Year = names[3]
+	}
+
+	if len(p.comment) > 0 {
+		fields := strings.Split(p.comment[0].name, ",")
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		sb.Brand = fields[0]
+		if len(fields) > 1 {
+			sb.Model = fields[1]
+		}
+		if len(fields) > 2 {
+			sb.Connection = fields[2]
+		}
+	}
+	return sb
+}
+
+func (ua *UserAgent) useStarboard(sb *Starboard) {
+	ua.Starboard = sb
+	if t := starboardDeviceTypes[sb.Type]; t != UnknownDevice {
+		ua.setType(t, ExplicitToken)
+	}
+
+	if ua.Device.Brand == "" {
+		ua.Device.Brand = sb.Brand
+	}
+	if ua.Device.Name == "" {
+		if sb.Model != "" {
+			ua.Device.set(sb.Model, ExplicitToken)
+		} else if sb.Chipset != "" {
+			ua.Device.set(sb.Chipset, Heuristic)
+		} else {
+			ua.Device.set(sb.Type, Heuristic)
+		}
+	}
+
+	if ua.OS.Name == "darwin" && ua.Device.Name == "ott" {
+		ua.Device.set("appletv", Heuristic)
+	}
+}
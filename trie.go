@@ -0,0 +1,59 @@
+package uaparser
+
+// prefixTrie indexes prefix recognizers by their prefix. A section is
+// offered to the recognizers of the longest matching prefix first, then to
+// the shorter ones ("windows nt " before "windows "), and within the same
+// prefix by descending priority, then in registration order. The first
+// handler accepting the section wins.
+type prefixTrie struct {
+	next  map[byte]*prefixTrie
+	recos []*recognizer
+}
+
+func newPrefixTrie() *prefixTrie {
+	return &prefixTrie{}
+}
+
+func (t *prefixTrie) add(reco *recognizer) {
+	if reco.prefix == "" || reco.handler == nil {
+		return
+	}
+
+	n := t
+	for i := 0; i < len(reco.prefix); i++ {
+		c := reco.prefix[i]
+		if n.next == nil {
+			n.next = make(map[byte]*prefixTrie)
+		}
+		child := n.next[c]
+		if child == nil {
+			child = &prefixTrie{}
+			n.next[c] = child
+		}
+		n = child
+	}
+
+	i := len(n.recos)
+	for i > 0 && n.recos[i-1].priority < reco.priority {
+		i--
+	}
+	n.recos = append(n.recos, nil)
+	copy(n.recos[i+1:], n.recos[i:])
+	n.recos[i] = reco
+}
+
+// try offers sec to the recognizers whose prefix is a prefix of s, the
+// longest first
+func (t *prefixTrie) try(ua *UserAgent, sec *section, s string) bool {
+	if len(s) > 0 {
+		if n := t.next[s[0]]; n != nil && n.try(ua, sec, s[1:]) {
+			return true
+		}
+	}
+	for _, reco := range t.recos {
+		if reco.handler(ua, reco, sec) {
+			return true
+		}
+	}
+	return false
+}
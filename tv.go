@@ -0,0 +1,176 @@
+package uaparser
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TV describes the smart TV platform, filled in when DeviceType is SmartTV.
+type TV struct {
+	Brand    string `json:"brand,omitempty"`    // samsung, lg, sony, panasonic, philips, hisense, tcl, vizio
+	Platform string `json:"platform,omitempty"` // tizen, webos, netcast, androidtv, rokuos, fireos, vidaa, smartcast
+	Year     int    `json:"year,omitempty"`     // model year, 0 if the UA does not encode it
+}
+
+type tvToken struct {
+	brand, platform string
+	tv              bool // the token alone identifies a TV
+	word            bool // short key, only matched as a whole word or before a delimiter (lg, lg-)
+}
+
+// matched against the start of every word of every section
+var tvTokens = map[string]tvToken{
+	"tizen":          {"samsung", "tizen", false, false},
+	"maple":          {"samsung", "", true, false},
+	"samsung":        {"samsung", "", false, false},
+	"samsungbrowser": {"samsung", "", false, false},
+	"smarttv20":      {"samsung", "", true, false}, // HbbTV/1.2.1 (+DRM;Samsung;SmartTV2015;...)
+
+	"webos.tv":   {"lg", "webos", true, false},
+	"web0s":      {"lg", "webos", true, false},
+	"netcast.tv": {"lg", "netcast", true, false},
+	"netcast":    {"lg", "netcast", true, false},
+	"lge":        {"lg", "", false, true},
+	"lg":         {"lg", "", false, true},
+
+	"bravia": {"sony", "", true, false},
+	"sony":   {"sony", "", false, true},
+
+	"viera":     {"panasonic", "", true, false},
+	"panasonic": {"panasonic", "", false, false},
+
+	"philipstv": {"philips", "", true, false},
+	"philips":   {"philips", "", false, false},
+	"nettv":     {"philips", "", true, false},
+
+	"vidaa":   {"hisense", "vidaa", true, false},
+	"hisense": {"hisense", "", false, false},
+
+	"tcl": {"tcl", "", false, true},
+
+	"smartcast": {"vizio", "smartcast", true, false},
+	"conjure":   {"vizio", "smartcast", true, false},
+	"vizio":     {"vizio", "", true, false},
+}
+
+// tvKeys are the keys of tvTokens, longest first so that webos.tv wins
+// over a shorter key matching the same word, map order is random
+var tvKeys = func() []string {
+	keys := make([]string, 0, len(tvTokens))
+	for k := range tvTokens {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// samsung ships one tizen release per model year
+var tizenYears = map[string]int{
+	"2.3": 2015,
+	"2.4": 2016,
+	"3.0": 2017,
+	"4.0": 2018,
+	"5.0": 2019,
+	"5.5": 2020,
+	"6.0": 2021,
+	"6.5": 2022,
+	"7.0": 2023,
+	"8.0": 2024,
+	"9.0": 2025,
+}
+
+// tvYear extracts the year from tokens like webos.tv-2017 or smarttv2015
+func tvYear(word string) int {
+	if len(word) < 4 {
+		return 0
+	}
+	y, err := strconv.Atoi(word[len(word)-4:])
+	if err != nil || y < 2008 || y > 2099 {
+		return 0
+	}
+	return y
+}
+
+// match reports whether word starts with the key k of t, "lg" matches lg
+// and lg-, but not lgbt
+func (t tvToken) match(word, k string) bool {
+	if !strings.HasPrefix(word, k) {
+		return false
+	}
+	if !t.word || len(word) == len(k) {
+		return true
+	}
+	c := word[len(k)]
+	return !isLetter(c) && !isDigit(c)
+}
+
+// scan reports whether name carries a token that identifies a TV
+func (tv *TV) scan(name string) bool {
+	found := false
+	for _, word := range strings.Fields(name) {
+		for _, k := range tvKeys {
+			t := tvTokens[k]
+			if !t.match(word, k) {
+				continue
+			}
+			if tv.Brand == "" {
+				tv.Brand = t.brand
+			}
+			if tv.Platform == "" {
+				tv.Platform = t.platform
+			}
+			if tv.Year == 0 {
+				tv.Year = tvYear(word)
+			}
+			found = found || t.tv
+		}
+	}
+	return found
+}
+
+// detectTV collects brand, platform and year from all sections and marks
+// the device as SmartTV when a token identifies one. The result is only
+// meaningful if the device ends up as SmartTV.
+func (ua *UserAgent) detectTV(products []product) TV {
+	var tv TV
+	isTV := false
+	for _, p := range products {
+		if p.section != nil && tv.scan(p.section.name) {
+			isTV = true
+		}
+		for _, sec := range p.comment {
+			if tv.scan(sec.name) {
+				isTV = true
+			}
+		}
+	}
+
+	switch {
+	case ua.OS.Name == "tizen":
+		tv.Platform = "tizen"
+		if tv.Year == 0 {
+			tv.Year = tizenYears[ua.OS.Version]
+		}
+	case ua.Device.Name == "roku" || ua.OS.Name == "rokuos":
+		tv.Platform = "rokuos"
+	case strings.HasPrefix(ua.Device.Name, "aft"): // Amazon Fire TV
+		tv.Platform = "fireos"
+	case ua.OS.Name == "android" && tv.Platform == "":
+		tv.Platform = "androidtv"
+	}
+
+	if tv.Brand == "" {
+		tv.Brand = ua.Device.Brand
+	}
+
+	if isTV && ua.DeviceType == UnknownDevice {
+		ua.setType(SmartTV, Heuristic)
+	}
+	return tv
+}
@@ -0,0 +1,245 @@
+package uaparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var (
+	uapCore      = flag.String("uapcore", "testdata/uap-core", "uap-core checkout for TestUAPCore")
+	uapCoreNames = flag.String("uapcore.names", "testdata/uapcore_names.json", "uap-core family to component name mapping")
+)
+
+// readUAPCore reads the test_cases of a uap-core fixture. The fixtures are
+// a flat list of maps, so a line reader does without a YAML dependency:
+//
+//	test_cases:
+//	  - user_agent_string: 'Mozilla/5.0 ...'
+//	    family: 'Firefox'
+//	    major: '3'
+func readUAPCore(r io.Reader) ([]map[string]string, error) {
+	var cases []map[string]string
+	in := bufio.NewScanner(r)
+	in.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; in.Scan(); n++ {
+		line := strings.TrimSpace(in.Text())
+		if line == "" || line[0] == '#' || line == "test_cases:" {
+			continue
+		}
+		if strings.HasPrefix(line, "- ") {
+			cases = append(cases, make(map[string]string))
+			line = strings.TrimSpace(line[2:])
+		}
+		if len(cases) == 0 {
+			return nil, fmt.Errorf("line %d: expected a test case", n)
+		}
+
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key: value", n)
+		}
+		v, err := yamlScalar(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		cases[len(cases)-1][line[:i]] = v
+	}
+	return cases, in.Err()
+}
+
+func yamlScalar(s string) (string, error) {
+	switch {
+	case s == "~" || s == "null":
+		return "", nil
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("unterminated string %s", s)
+		}
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	case strings.HasPrefix(s, "\""):
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+// uapNames maps lower cased uap-core families to component names,
+// alternatives are separated by |, families missing from the map are
+// expected as is
+type uapNames map[string]map[string]string
+
+func (m uapNames) matches(component, family, name string) bool {
+	family = strings.ToLower(family)
+	if family == "other" {
+		return name == ""
+	}
+	expected, ok := m[component][family]
+	if !ok {
+		expected = family
+	}
+	for _, x := range strings.Split(expected, "|") {
+		if x == name {
+			return true
+		}
+	}
+	return false
+}
+
+// versionPart returns the i-th number of 10.0.1 or 13_4
+func versionPart(version string, i int) string {
+	parts := strings.FieldsFunc(version, func(r rune) bool { return r == '.' || r == '_' })
+	if i < len(parts) {
+		return strings.TrimLeft(parts[i], "0")
+	}
+	return ""
+}
+
+type uapScore struct {
+	total  int
+	ok     map[string]int
+	missed map[string]int // expected family -> count
+}
+
+func (s *uapScore) add(family string, fields map[string]bool) {
+	s.total++
+	for k, ok := range fields {
+		n := s.ok[k]
+		if ok {
+			n++
+		} else if k == "family" {
+			s.missed[family]++
+		}
+		s.ok[k] = n
+	}
+}
+
+// TestUAPCore runs the uap-core fixtures in -uapcore as a conformance suite
+// and prints a scoreboard, it does not fail on mismatches:
+//
+//	git clone https://github.com/ua-parser/uap-core testdata/uap-core
+//	go test -run UAPCore -v
+func TestUAPCore(t *testing.T) {
+	if _, err := os.Stat(filepath.Join(*uapCore, "tests")); err != nil {
+		t.Skipf("no uap-core fixtures in %s", *uapCore)
+	}
+
+	b, err := os.ReadFile(*uapCoreNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names uapNames
+	if err := json.Unmarshal(b, &names); err != nil {
+		t.Fatalf("%s: %v", *uapCoreNames, err)
+	}
+
+	version := func(c map[string]string, v string) map[string]bool {
+		return map[string]bool{
+			"major": strings.TrimLeft(c["major"], "0") == versionPart(v, 0),
+			"minor": c["minor"] == "" || strings.TrimLeft(c["minor"], "0") == versionPart(v, 1),
+		}
+	}
+	fixtures := []struct {
+		file  string
+		check func(ua *UserAgent, c map[string]string) map[string]bool
+	}{
+		{"test_ua.yaml", func(ua *UserAgent, c map[string]string) map[string]bool {
+			fields := version(c, ua.Browser.Version)
+			fields["family"] = names.matches("browser", c["family"], ua.Browser.Name)
+			return fields
+		}},
+		{"test_os.yaml", func(ua *UserAgent, c map[string]string) map[string]bool {
+			fields := version(c, ua.OS.Version)
+			fields["family"] = names.matches("os", c["family"], ua.OS.Name)
+			return fields
+		}},
+		{"test_device.yaml", func(ua *UserAgent, c map[string]string) map[string]bool {
+			return map[string]bool{
+				"family": names.matches("device", c["family"], ua.Device.Name) || (c["model"] != "" && strings.ToLower(c["model"]) == ua.Device.Name),
+				"brand":  strings.ToLower(c["brand"]) == ua.Device.Brand,
+			}
+		}},
+	}
+
+	for _, x := range fixtures {
+		f, err := os.Open(filepath.Join(*uapCore, "tests", x.file))
+		if err != nil {
+			t.Logf("%s: %v", x.file, err)
+			continue
+		}
+		cases, err := readUAPCore(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", x.file, err)
+		}
+
+		score := &uapScore{ok: make(map[string]int), missed: make(map[string]int)}
+		for _, c := range cases {
+			score.add(c["family"], x.check(Parse(c["user_agent_string"]), c))
+		}
+		if score.total == 0 {
+			continue
+		}
+
+		fields := make([]string, 0, len(score.ok))
+		for k := range score.ok {
+			fields = append(fields, k)
+		}
+		sort.Strings(fields)
+		for _, k := range fields {
+			t.Logf("%-16s %-8s %6.2f%% (%d/%d)", x.file, k, 100*float64(score.ok[k])/float64(score.total), score.ok[k], score.total)
+		}
+
+		missed := make([]string, 0, len(score.missed))
+		for k := range score.missed {
+			missed = append(missed, k)
+		}
+		sort.Slice(missed, func(i, j int) bool {
+			if score.missed[missed[i]] != score.missed[missed[j]] {
+				return score.missed[missed[i]] > score.missed[missed[j]]
+			}
+			return missed[i] < missed[j]
+		})
+		if len(missed) > 10 {
+			missed = missed[:10]
+		}
+		for _, k := range missed {
+			t.Logf("%-16s missed %-24s %d", x.file, k, score.missed[k])
+		}
+	}
+}
+
+func TestReadUAPCore(t *testing.T) {
+	s := `test_cases:
+
+  - user_agent_string: 'Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0'
+    family: 'Firefox'
+    major: '121'
+    minor: '0'
+    patch:
+
+  - user_agent_string: "It's \"quoted\""
+    family: 'O''Reilly'
+    major: ~
+`
+	cases, err := readUAPCore(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) != 2 || cases[0]["family"] != "Firefox" || cases[0]["major"] != "121" || cases[0]["patch"] != "" ||
+		cases[1]["user_agent_string"] != `It's "quoted"` || cases[1]["family"] != "O'Reilly" || cases[1]["major"] != "" {
+		t.Errorf("got: %q\n", cases)
+	}
+
+	names := uapNames{"browser": {"opera": "opr|opera"}}
+	if !names.matches("browser", "Opera", "opr") || !names.matches("browser", "Firefox", "firefox") || !names.matches("browser", "Other", "") || names.matches("browser", "Other", "chrome") {
+		t.Errorf("uapNames.matches\n")
+	}
+}